@@ -0,0 +1,216 @@
+package ar
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrHeader = fmt.Errorf("ar: invalid header")
+
+const (
+	magic      = "!<arch>\n"
+	headerSize = 60
+	maxNames   = 16 << 20 // size of the gnu long name table
+	maxName    = 4096     // size of a bsd long name
+)
+
+// Header is the header of a file in an ar archive
+type Header struct {
+	Name    string
+	ModTime time.Time
+	Uid     int
+	Gid     int
+	Mode    fs.FileMode
+	Size    int64
+}
+
+// Reader reads the files of an ar archive (common, gnu and bsd variants)
+// Next moves to the next file and Read reads the data of that file
+type Reader struct {
+	r         io.Reader
+	err       error
+	remaining int64
+	pad       int64
+	names     []byte // gnu long name table
+	started   bool
+}
+
+// NewReader Creates a new ar reader
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+func (r *Reader) readMagic() error {
+	buf := make([]byte, len(magic))
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrHeader
+		}
+		return err
+	}
+	if string(buf) != magic {
+		return ErrHeader
+	}
+	return nil
+}
+
+// skip the rest of the current file and its padding
+func (r *Reader) skip() error {
+	if _, err := io.CopyN(io.Discard, r.r, r.remaining+r.pad); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	r.remaining = 0
+	r.pad = 0
+	return nil
+}
+
+// Next advances to the next file in the archive, io.EOF is returned at the end
+func (r *Reader) Next() (*Header, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	hdr, err := r.next()
+	r.err = err
+	return hdr, err
+}
+
+func (r *Reader) next() (*Header, error) {
+	if !r.started {
+		r.started = true
+		if err := r.readMagic(); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		if err := r.skip(); err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, headerSize)
+		if _, err := io.ReadFull(r.r, buf); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return nil, ErrHeader
+			}
+			return nil, err
+		}
+		if string(buf[58:60]) != "`\n" {
+			return nil, ErrHeader
+		}
+
+		hdr := &Header{}
+		var err error
+		if hdr.Size, err = parseInt(buf[48:58], 10); err != nil {
+			return nil, err
+		}
+		r.remaining = hdr.Size
+		r.pad = hdr.Size % 2
+
+		name := strings.TrimRight(string(buf[0:16]), " ")
+		switch {
+		case name == "/" || name == "/SYM64/" || name == "__.SYMDEF" || name == "__.SYMDEF SORTED":
+			// symbol table
+			continue
+		case name == "//":
+			// gnu long name table
+			if hdr.Size > maxNames {
+				return nil, ErrHeader
+			}
+			r.names = make([]byte, hdr.Size)
+			if _, err := io.ReadFull(r.r, r.names); err != nil {
+				return nil, err
+			}
+			r.remaining = 0
+			continue
+		case strings.HasPrefix(name, "#1/"):
+			// bsd long name, stored at the start of the data
+			size, err := parseInt([]byte(name[3:]), 10)
+			if err != nil || size > hdr.Size || size > maxName {
+				return nil, ErrHeader
+			}
+			nameBuf := make([]byte, size)
+			if _, err := io.ReadFull(r.r, nameBuf); err != nil {
+				return nil, err
+			}
+			name = string(bytes.TrimRight(nameBuf, "\x00"))
+			hdr.Size -= size
+			r.remaining = hdr.Size
+		case strings.HasPrefix(name, "/"):
+			// gnu long name, offset into the name table
+			offset, err := parseInt([]byte(name[1:]), 10)
+			if err != nil || offset >= int64(len(r.names)) {
+				return nil, ErrHeader
+			}
+			name = string(r.names[offset:])
+			if i := strings.Index(name, "/\n"); i >= 0 {
+				name = name[:i]
+			}
+		default:
+			name = strings.TrimSuffix(name, "/")
+		}
+		hdr.Name = name
+
+		mtime, err := parseInt(buf[16:28], 10)
+		if err != nil {
+			return nil, err
+		}
+		hdr.ModTime = time.Unix(mtime, 0)
+		uid, err := parseInt(buf[28:34], 10)
+		if err != nil {
+			return nil, err
+		}
+		hdr.Uid = int(uid)
+		gid, err := parseInt(buf[34:40], 10)
+		if err != nil {
+			return nil, err
+		}
+		hdr.Gid = int(gid)
+		mode, err := parseInt(buf[40:48], 8)
+		if err != nil {
+			return nil, err
+		}
+		hdr.Mode = fs.FileMode(mode).Perm()
+		return hdr, nil
+	}
+}
+
+// Read reads from the current file in the archive, returns io.EOF at the end of the file
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	if err == io.EOF && r.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// parseInt parses a space padded unsigned number, empty fields are 0
+func parseInt(b []byte, base int) (int64, error) {
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return 0, nil
+	}
+	// unsigned so a sign (and negative sizes) is invalid
+	v, err := strconv.ParseUint(s, base, 63)
+	if err != nil {
+		return 0, ErrHeader
+	}
+	return int64(v), nil
+}
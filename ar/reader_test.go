@@ -0,0 +1,116 @@
+package ar
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+type arFile struct {
+	name string
+	data string
+}
+
+func assertArchive(t *testing.T, r *Reader, exp []arFile) {
+	t.Helper()
+	for _, e := range exp {
+		hdr, err := r.Next()
+		if err != nil {
+			t.Fatalf("expected nil error for next, got %v", err)
+		}
+		if hdr.Name != e.name {
+			t.Errorf("expected %s name, got %s", e.name, hdr.Name)
+		}
+		if hdr.Size != int64(len(e.data)) {
+			t.Errorf("expected %d size, got %d", len(e.data), hdr.Size)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("expected nil error for read, got %v", err)
+		}
+		if string(data) != e.data {
+			t.Errorf("expected %s, got %s", e.data, string(data))
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected EOF for next, got %v", err)
+	}
+}
+
+func TestReader(t *testing.T) {
+	t.Run("gnu", func(t *testing.T) {
+		// generated with: ar rcD foo.a foo.txt a_very_long_file_name_here.txt
+		f, err := os.Open("testdata/foo.a")
+		if err != nil {
+			t.Fatalf("failed to open ar file %v", err)
+		}
+		defer f.Close()
+		r := NewReader(f)
+		assertArchive(t, r, []arFile{
+			{"foo.txt", "foo bar"},
+			{"a_very_long_file_name_here.txt", "a longer file\n"},
+		})
+	})
+
+	t.Run("bsd", func(t *testing.T) {
+		var buf bytes.Buffer
+		buf.WriteString(magic)
+		fmt.Fprintf(&buf, "%-16s%-12d%-6d%-6d%-8o%-10d`\n", "#1/20", 0, 0, 0, 0644, 20+3)
+		buf.WriteString("a_long_bsd_name.txt\x00abc\n")
+		fmt.Fprintf(&buf, "%-16s%-12d%-6d%-6d%-8o%-10d`\n", "short", 0, 0, 0, 0644, 2)
+		buf.WriteString("hi")
+		r := NewReader(&buf)
+		assertArchive(t, r, []arFile{
+			{"a_long_bsd_name.txt", "abc"},
+			{"short", "hi"},
+		})
+	})
+
+	t.Run("skips unread data", func(t *testing.T) {
+		f, err := os.Open("testdata/foo.a")
+		if err != nil {
+			t.Fatalf("failed to open ar file %v", err)
+		}
+		defer f.Close()
+		r := NewReader(f)
+		if _, err := r.Next(); err != nil {
+			t.Fatalf("expected nil error for next, got %v", err)
+		}
+		hdr, err := r.Next()
+		if err != nil {
+			t.Fatalf("expected nil error for next, got %v", err)
+		}
+		if hdr.Name != "a_very_long_file_name_here.txt" {
+			t.Errorf("expected a_very_long_file_name_here.txt name, got %s", hdr.Name)
+		}
+	})
+
+	t.Run("invalid sizes", func(t *testing.T) {
+		header := func(name, size string) string {
+			return fmt.Sprintf("%-16s%-12s%-6s%-6s%-8s%-10s`\n", name, "0", "0", "0", "644", size)
+		}
+		tests := map[string]string{
+			"negative name table": header("//", "-1"),
+			"large name table":    header("//", "9999999999"),
+			"negative bsd name":   header("#1/-5", "10") + "0123456789",
+			"large bsd name":      header("#1/999999", "9999999999"),
+			"negative size":       header("foo.txt/", "-10"),
+			"signed size":         header("foo.txt/", "+10") + "0123456789",
+		}
+		for name, content := range tests {
+			r := NewReader(bytes.NewReader([]byte(magic + content)))
+			if _, err := r.Next(); err != ErrHeader {
+				t.Errorf("expected header error for %s, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("not ar", func(t *testing.T) {
+		r := NewReader(bytes.NewReader([]byte("Something cool")))
+		if _, err := r.Next(); err != ErrHeader {
+			t.Errorf("expected header error for next, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package archive
+
+import (
+	"io"
+
+	"github.com/jonathongardner/fifo/identifiers"
+)
+
+// Options is a struct that contains options for walking an archive
+type Options struct {
+	Identifiers identifiers.Options
+	MemorySize  int    // max bytes of a zip to keep in memory before using a temp file
+	TmpDir      string // directory for temp files, empty uses the default temp directory
+}
+
+// NewDefultOptions creates a new Options struct with default values
+// default identifiers and 10MB kept in memory
+func NewDefultOptions() Options {
+	return NewOptions(identifiers.NewDefultOptions(), 10*1024*1024, "")
+}
+
+// NewOptions creates a new Options struct with the given options
+func NewOptions(o identifiers.Options, memorySize int, tmpDir string) Options {
+	return Options{
+		Identifiers: o,
+		MemorySize:  memorySize,
+		TmpDir:      tmpDir,
+	}
+}
+
+// UpdateIdentifiers updates the identifiers options of the Options struct
+func (o Options) UpdateIdentifiers(i identifiers.Options) Options {
+	o.Identifiers = i
+	return o
+}
+
+// UpdateMemorySize updates the memory size of the Options struct
+func (o Options) UpdateMemorySize(size int) Options {
+	o.MemorySize = size
+	return o
+}
+
+// UpdateTmpDir updates the temp directory of the Options struct
+func (o Options) UpdateTmpDir(dir string) Options {
+	o.TmpDir = dir
+	return o
+}
+
+// NewWalker creates a new Walker, detecting if r is a tar or zip
+func (o Options) NewWalker(r io.Reader) (*Walker, error) {
+	return newWalkerWithOptions(o, r)
+}
+
+// NewTarWalker creates a new Walker for a tar archive
+func (o Options) NewTarWalker(r io.Reader) *Walker {
+	return newTarWalker(o, r)
+}
+
+// NewZipWalker creates a new Walker for a zip archive
+// if r isnt an io.ReaderAt and io.Seeker it is spooled to memory or a temp file first
+func (o Options) NewZipWalker(r io.Reader) (*Walker, error) {
+	return newZipWalker(o, r)
+}
@@ -0,0 +1,276 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jonathongardner/fifo/buffer"
+	"github.com/jonathongardner/fifo/filetype"
+	"github.com/jonathongardner/fifo/identifiers"
+)
+
+var ErrNotArchive = fmt.Errorf("not a tar or zip archive")
+var ErrLinkname = fmt.Errorf("symlink target is too long")
+
+// maxLinkname is the longest symlink target read from a zip entry
+const maxLinkname = 4096
+
+// Header is the metadata of an entry in an archive
+type Header struct {
+	Name     string      `json:"name"`
+	Mode     fs.FileMode `json:"mode"`
+	ModTime  time.Time   `json:"mod_time"`
+	Linkname string      `json:"linkname,omitempty"`
+	Hardlink bool        `json:"hardlink,omitempty"` // Linkname is the entry it is a hard link to
+	Size     int64       `json:"size"`
+}
+
+// Entry is an entry in an archive with its identifiers
+// directories and links are not read, their filetype is filetype.Dir and filetype.Symlink
+type Entry struct {
+	Header      Header                  `json:"header"`
+	Identifiers identifiers.Identifiers `json:"identifiers"`
+}
+
+// Walker iterates over the entries of an archive, calculating the identifiers of each
+type Walker struct {
+	next    func() (Header, io.Reader, error)
+	writer  *identifiers.Writer
+	cleanup func() error
+	err     error
+}
+
+// NewWalker creates a new Walker with default options, detecting if r is a tar or zip
+func NewWalker(r io.Reader) (*Walker, error) {
+	return newWalkerWithOptions(NewDefultOptions(), r)
+}
+
+// NewTarWalker creates a new Walker with default options for a tar archive
+func NewTarWalker(r io.Reader) *Walker {
+	return newTarWalker(NewDefultOptions(), r)
+}
+
+// NewZipWalker creates a new Walker with default options for a zip archive
+func NewZipWalker(r io.Reader) (*Walker, error) {
+	return newZipWalker(NewDefultOptions(), r)
+}
+
+func newWalkerWithOptions(o Options, r io.Reader) (*Walker, error) {
+	head := make([]byte, filetype.MaxBytesFileDetect())
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+	ftype := filetype.NewFiletypeFromBytes(head)
+
+	// if seekable go back to the start so zip can use it without spooling
+	if s, ok := r.(io.Seeker); ok {
+		if _, err := s.Seek(-int64(n), io.SeekCurrent); err != nil {
+			return nil, err
+		}
+	} else {
+		r = io.MultiReader(bytes.NewReader(head), r)
+	}
+
+	switch {
+	case ftype.Is("application/x-tar"):
+		return newTarWalker(o, r), nil
+	case ftype.Is("application/zip"):
+		return newZipWalker(o, r)
+	}
+	return nil, ErrNotArchive
+}
+
+func newTarWalker(o Options, r io.Reader) *Walker {
+	tr := tar.NewReader(r)
+	next := func() (Header, io.Reader, error) {
+		hdr, err := tr.Next()
+		if err != nil {
+			return Header{}, nil, err
+		}
+		toReturn := Header{
+			Name:     hdr.Name,
+			Mode:     hdr.FileInfo().Mode(),
+			ModTime:  hdr.ModTime,
+			Linkname: hdr.Linkname,
+			Hardlink: hdr.Typeflag == tar.TypeLink,
+			Size:     hdr.Size,
+		}
+		return toReturn, tr, nil
+	}
+	return &Walker{next: next, writer: o.Identifiers.NewWriter(), cleanup: func() error { return nil }}
+}
+
+func newZipWalker(o Options, r io.Reader) (*Walker, error) {
+	readerAt, size, cleanup, err := zipSource(o, r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	i := 0
+	var current io.ReadCloser
+	next := func() (Header, io.Reader, error) {
+		if current != nil {
+			current.Close()
+			current = nil
+		}
+		if i >= len(zr.File) {
+			return Header{}, nil, io.EOF
+		}
+		f := zr.File[i]
+		i++
+		hdr := Header{
+			Name:    f.Name,
+			Mode:    f.Mode(),
+			ModTime: f.Modified,
+			Size:    int64(f.UncompressedSize64),
+		}
+		if !hdr.Mode.IsRegular() {
+			if hdr.Mode&fs.ModeSymlink != 0 {
+				// the link target is the data of the entry
+				link, err := readLinkname(f)
+				if err != nil {
+					return Header{}, nil, err
+				}
+				hdr.Linkname = string(link)
+			}
+			return hdr, nil, nil
+		}
+		current, err = f.Open()
+		if err != nil {
+			return Header{}, nil, err
+		}
+		return hdr, current, nil
+	}
+	closeAll := func() error {
+		if current != nil {
+			current.Close()
+		}
+		return cleanup()
+	}
+	return &Walker{next: next, writer: o.Identifiers.NewWriter(), cleanup: closeAll}, nil
+}
+
+// readLinkname reads the symlink target stored as the data of a zip entry
+func readLinkname(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	// the size in the header cant be trusted so limit what is read
+	link, err := io.ReadAll(io.LimitReader(r, maxLinkname+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(link) > maxLinkname {
+		return nil, ErrLinkname
+	}
+	return link, nil
+}
+
+// zipSource returns a ReaderAt for r, spooling it to memory or a temp file if needed
+func zipSource(o Options, r io.Reader) (io.ReaderAt, int64, func() error, error) {
+	if ra, ok := r.(io.ReaderAt); ok {
+		if s, ok := r.(io.Seeker); ok {
+			size, err := s.Seek(0, io.SeekEnd)
+			if err != nil {
+				return nil, 0, nil, err
+			}
+			return ra, size, func() error { return nil }, nil
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp(o.TmpDir, "archive")
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	spool, err := buffer.NewSpool(filepath.Join(tmpDir, "spool"), o.MemorySize)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, 0, nil, err
+	}
+	deleteAll := func() error {
+		spool.Delete()
+		return os.RemoveAll(tmpDir)
+	}
+
+	if _, err := io.Copy(spool, r); err != nil {
+		deleteAll()
+		return nil, 0, nil, err
+	}
+	if err := spool.Close(); err != nil {
+		deleteAll()
+		return nil, 0, nil, err
+	}
+	reader, err := spool.NewReader()
+	if err != nil {
+		deleteAll()
+		return nil, 0, nil, err
+	}
+	cleanup := func() error {
+		reader.Close()
+		return deleteAll()
+	}
+	return reader, spool.Size(), cleanup, nil
+}
+
+// Next returns the next entry in the archive, io.EOF is returned at the end
+func (w *Walker) Next() (*Entry, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	hdr, r, err := w.next()
+	if err != nil {
+		w.err = err
+		return nil, err
+	}
+
+	entry := &Entry{Header: hdr}
+	switch {
+	case hdr.Mode.IsDir():
+		entry.Identifiers = identifiers.Identifiers{Filetype: filetype.Dir}
+		return entry, nil
+	case hdr.Hardlink || hdr.Mode&fs.ModeSymlink != 0:
+		// hard links have no data, they are treated like a symlink
+		entry.Identifiers = identifiers.Identifiers{Filetype: filetype.Symlink}
+		return entry, nil
+	case !hdr.Mode.IsRegular() || r == nil:
+		// devices, pipes etc have no data
+		return entry, nil
+	}
+
+	w.writer.Reset()
+	if _, err := io.Copy(w.writer, r); err != nil {
+		w.err = err
+		return nil, err
+	}
+	w.writer.Close()
+	entry.Identifiers, err = w.writer.Identifiers()
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Close cleans up any temp files, it doesnt close the reader passed in
+func (w *Walker) Close() error {
+	if w.err == os.ErrClosed {
+		return os.ErrClosed
+	}
+	w.err = os.ErrClosed
+	return w.cleanup()
+}
@@ -0,0 +1,232 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jonathongardner/fifo/filetype"
+)
+
+var modTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func tarArchive() ([]byte, error) {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	headers := []struct {
+		hdr  *tar.Header
+		data []byte
+	}{
+		{&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755, ModTime: modTime}, nil},
+		{&tar.Header{Name: "dir/foo.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 14, ModTime: modTime}, []byte("Something cool")},
+		{&tar.Header{Name: "dir/link", Typeflag: tar.TypeSymlink, Linkname: "foo.txt", ModTime: modTime}, nil},
+		{&tar.Header{Name: "dir/hard", Typeflag: tar.TypeLink, Linkname: "dir/foo.txt", ModTime: modTime}, nil},
+	}
+	for _, h := range headers {
+		if err := writer.WriteHeader(h.hdr); err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(h.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func zipArchive() ([]byte, error) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	if _, err := writer.CreateHeader(&zip.FileHeader{Name: "dir/", Modified: modTime}); err != nil {
+		return nil, err
+	}
+	f, err := writer.CreateHeader(&zip.FileHeader{Name: "dir/foo.txt", Modified: modTime})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write([]byte("Something cool")); err != nil {
+		return nil, err
+	}
+	link := &zip.FileHeader{Name: "dir/link", Modified: modTime}
+	link.SetMode(fs.ModeSymlink | 0777)
+	f, err = writer.CreateHeader(link)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write([]byte("foo.txt")); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type expEntry struct {
+	name     string
+	mime     string
+	linkname string
+	md5      string
+}
+
+func assertWalker(t *testing.T, w *Walker, exp []expEntry) {
+	t.Helper()
+	for _, e := range exp {
+		entry, err := w.Next()
+		if err != nil {
+			t.Fatalf("expected nil error for next, got %v", err)
+		}
+		if entry.Header.Name != e.name {
+			t.Errorf("expected %s name, got %s", e.name, entry.Header.Name)
+		}
+		if !entry.Header.ModTime.Equal(modTime) {
+			t.Errorf("expected %v mod time for %s, got %v", modTime, e.name, entry.Header.ModTime)
+		}
+		if entry.Header.Linkname != e.linkname {
+			t.Errorf("expected %s link for %s, got %s", e.linkname, e.name, entry.Header.Linkname)
+		}
+		if entry.Identifiers.Filetype.Mimetype != e.mime {
+			t.Errorf("expected %s mimetype for %s, got %s", e.mime, e.name, entry.Identifiers.Filetype.Mimetype)
+		}
+		if entry.Identifiers.Md5 != e.md5 {
+			t.Errorf("expected %s md5 for %s, got %s", e.md5, e.name, entry.Identifiers.Md5)
+		}
+	}
+	if _, err := w.Next(); err != io.EOF {
+		t.Errorf("expected EOF for next, got %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("expected nil error for close, got %v", err)
+	}
+}
+
+// notSeekable hides the ReaderAt and Seeker of a bytes.Reader
+type notSeekable struct {
+	io.Reader
+}
+
+func TestWalker(t *testing.T) {
+	tarData, err := tarArchive()
+	if err != nil {
+		t.Fatalf("failed to create tar %v", err)
+	}
+	zipData, err := zipArchive()
+	if err != nil {
+		t.Fatalf("failed to create zip %v", err)
+	}
+	fooMd5 := "db5ee56e2cab72f4e46bdd60965bef31"
+
+	tarExp := []expEntry{
+		{"dir/", filetype.Dir.Mimetype, "", ""},
+		{"dir/foo.txt", "text/plain; charset=utf-8", "", fooMd5},
+		{"dir/link", filetype.Symlink.Mimetype, "foo.txt", ""},
+		{"dir/hard", filetype.Symlink.Mimetype, "dir/foo.txt", ""},
+	}
+	zipExp := []expEntry{
+		{"dir/", filetype.Dir.Mimetype, "", ""},
+		{"dir/foo.txt", "text/plain; charset=utf-8", "", fooMd5},
+		{"dir/link", filetype.Symlink.Mimetype, "foo.txt", ""},
+	}
+
+	t.Run("tar", func(t *testing.T) {
+		assertWalker(t, NewTarWalker(bytes.NewReader(tarData)), tarExp)
+	})
+
+	t.Run("zip seekable", func(t *testing.T) {
+		w, err := NewZipWalker(bytes.NewReader(zipData))
+		if err != nil {
+			t.Fatalf("failed to create zip walker %v", err)
+		}
+		assertWalker(t, w, zipExp)
+	})
+
+	t.Run("zip not seekable", func(t *testing.T) {
+		w, err := NewZipWalker(notSeekable{bytes.NewReader(zipData)})
+		if err != nil {
+			t.Fatalf("failed to create zip walker %v", err)
+		}
+		assertWalker(t, w, zipExp)
+	})
+
+	t.Run("zip temp file", func(t *testing.T) {
+		tmpDir, err := os.MkdirTemp("", "tmp")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		o := NewDefultOptions().UpdateMemorySize(10).UpdateTmpDir(tmpDir)
+		w, err := o.NewZipWalker(notSeekable{bytes.NewReader(zipData)})
+		if err != nil {
+			t.Fatalf("failed to create zip walker %v", err)
+		}
+		assertWalker(t, w, zipExp)
+
+		entries, err := os.ReadDir(tmpDir)
+		if err != nil {
+			t.Fatalf("failed to read temp dir %v", err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("expected temp files to be cleaned up, got %d", len(entries))
+		}
+	})
+
+	t.Run("detect", func(t *testing.T) {
+		w, err := NewWalker(notSeekable{bytes.NewReader(tarData)})
+		if err != nil {
+			t.Fatalf("failed to create walker %v", err)
+		}
+		assertWalker(t, w, tarExp)
+
+		w, err = NewWalker(bytes.NewReader(zipData))
+		if err != nil {
+			t.Fatalf("failed to create walker %v", err)
+		}
+		assertWalker(t, w, zipExp)
+	})
+
+	t.Run("links", func(t *testing.T) {
+		w := NewTarWalker(bytes.NewReader(tarData))
+		defer w.Close()
+		for i := 0; i < 3; i++ {
+			w.Next()
+		}
+		// a hard link keeps its mode and isnt reported as a symlink
+		entry, err := w.Next()
+		if err != nil {
+			t.Fatalf("expected nil error for next, got %v", err)
+		}
+		if !entry.Header.Hardlink || entry.Header.Mode&fs.ModeSymlink != 0 || !entry.Header.Mode.IsRegular() {
+			t.Errorf("expected regular hard link, got %+v", entry.Header)
+		}
+
+		var buf bytes.Buffer
+		writer := zip.NewWriter(&buf)
+		link := &zip.FileHeader{Name: "link", Modified: modTime, Method: zip.Deflate}
+		link.SetMode(fs.ModeSymlink | 0777)
+		f, _ := writer.CreateHeader(link)
+		f.Write(make([]byte, 1<<20))
+		writer.Close()
+		zw, err := NewZipWalker(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("failed to create zip walker %v", err)
+		}
+		defer zw.Close()
+		if _, err := zw.Next(); err != ErrLinkname {
+			t.Errorf("expected linkname error for a large symlink, got %v", err)
+		}
+	})
+
+	t.Run("not archive", func(t *testing.T) {
+		if _, err := NewWalker(bytes.NewReader([]byte("Something cool"))); err != ErrNotArchive {
+			t.Errorf("expected not archive error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package buffer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// SpoolReader reads back the data written to a Spool
+type SpoolReader interface {
+	io.ReadSeekCloser
+	io.ReaderAt
+}
+
+// Spool is a writer that keeps data in memory until it reaches a threshold, then
+// writes it to a file (see FileWriter). Once closed the data can be read back as
+// many times as needed with NewReader, which is needed for formats like zip that
+// require random access
+type Spool struct {
+	writer *FileWriter
+	size   int64
+	closed bool
+}
+
+// NewSpool creates a new Spool, the file at filePath is only created if more than max bytes are written
+func NewSpool(filePath string, max int) (*Spool, error) {
+	writer, err := NewFileWriter(filePath, max)
+	if err != nil {
+		return nil, err
+	}
+	return &Spool{writer: writer}, nil
+}
+
+// Write writes data to the spool
+func (s *Spool) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, os.ErrClosed
+	}
+	n, err := s.writer.Write(p)
+	s.size += int64(n)
+	return n, err
+}
+
+// Size returns the number of bytes written
+func (s *Spool) Size() int64 {
+	return s.size
+}
+
+// InMemory returns true if the data was never written to a file
+func (s *Spool) InMemory() bool {
+	return s.writer.file == nil
+}
+
+// Close finishes writing, flushing to the file if the data didnt fit in memory
+func (s *Spool) Close() error {
+	if s.closed {
+		return os.ErrClosed
+	}
+	s.closed = true
+	if s.InMemory() {
+		return nil
+	}
+	return s.writer.Close()
+}
+
+type memoryReader struct {
+	*bytes.Reader
+}
+
+func (m memoryReader) Close() error {
+	return nil
+}
+
+// NewReader Create a new reader from the start of the spooled data
+func (s *Spool) NewReader() (SpoolReader, error) {
+	if !s.closed {
+		return nil, fmt.Errorf("spool is not closed")
+	}
+	if s.writer.max == -2 {
+		return nil, fmt.Errorf("spool deleted")
+	}
+	if s.InMemory() {
+		return memoryReader{bytes.NewReader(s.writer.buffer)}, nil
+	}
+	return os.Open(s.writer.filePath)
+}
+
+// Delete removes the file (if created) and frees the memory
+// readers created from the spool should be closed first
+func (s *Spool) Delete() error {
+	s.closed = true
+	if s.writer.max == -2 {
+		return nil
+	}
+	if s.InMemory() || s.writer.max != -1 {
+		s.writer.buffer = nil
+		return s.writer.Delete()
+	}
+	// already closed so remove the file
+	s.writer.max = -2
+	s.writer.file = nil
+	if err := os.Remove(s.writer.filePath); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package buffer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func assertSpoolReader(t *testing.T, s *Spool, exp []byte) {
+	t.Helper()
+	for i := 0; i < 2; i++ {
+		r, err := s.NewReader()
+		if err != nil {
+			t.Fatalf("Failed to create reader %v", err)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Failed to read %v", err)
+		}
+		if string(data) != string(exp) {
+			t.Errorf("Expected %s, got %s", string(exp), string(data))
+		}
+		if err := r.Close(); err != nil {
+			t.Fatalf("Failed to close reader %v", err)
+		}
+	}
+}
+
+func TestSpool(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "tmp")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	toWrite1 := []byte("Something cool")
+
+	t.Run("in memory", func(t *testing.T) {
+		file := filepath.Join(tmpDir, "test-memory")
+		s, err := NewSpool(file, len(toWrite1)+1)
+		if err != nil {
+			t.Fatalf("Failed to create new spool %v", err)
+		}
+		if _, err := s.NewReader(); err == nil {
+			t.Errorf("Expected error creating reader before close")
+		}
+
+		if _, err := s.Write(toWrite1); err != nil {
+			t.Fatalf("Failed to write %v", err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("Failed to close %v", err)
+		}
+		if !s.InMemory() {
+			t.Errorf("Expected in memory")
+		}
+		if s.Size() != int64(len(toWrite1)) {
+			t.Errorf("Expected %d size, got %d", len(toWrite1), s.Size())
+		}
+		assertFileDoesNotExist(t, file)
+		assertSpoolReader(t, s, toWrite1)
+
+		if err := s.Delete(); err != nil {
+			t.Fatalf("Failed to delete %v", err)
+		}
+		if _, err := s.NewReader(); err == nil {
+			t.Errorf("Expected error creating reader after delete")
+		}
+	})
+
+	t.Run("in file", func(t *testing.T) {
+		file := filepath.Join(tmpDir, "test-file")
+		s, err := NewSpool(file, len(toWrite1)+1)
+		if err != nil {
+			t.Fatalf("Failed to create new spool %v", err)
+		}
+
+		exp := append(toWrite1, toWrite1...)
+		if _, err := s.Write(toWrite1); err != nil {
+			t.Fatalf("Failed to write first %v", err)
+		}
+		if _, err := s.Write(toWrite1); err != nil {
+			t.Fatalf("Failed to write second %v", err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("Failed to close %v", err)
+		}
+		if s.InMemory() {
+			t.Errorf("Expected not in memory")
+		}
+		assertFileExists(t, file, exp)
+		assertSpoolReader(t, s, exp)
+
+		if err := s.Delete(); err != nil {
+			t.Fatalf("Failed to delete %v", err)
+		}
+		assertFileDoesNotExist(t, file)
+	})
+
+	t.Run("delete before close", func(t *testing.T) {
+		file := filepath.Join(tmpDir, "test-delete")
+		s, err := NewSpool(file, len(toWrite1))
+		if err != nil {
+			t.Fatalf("Failed to create new spool %v", err)
+		}
+		if _, err := s.Write(toWrite1); err != nil {
+			t.Fatalf("Failed to write %v", err)
+		}
+		assertFileExists(t, file, toWrite1)
+
+		if err := s.Delete(); err != nil {
+			t.Fatalf("Failed to delete %v", err)
+		}
+		assertFileDoesNotExist(t, file)
+		if _, err := s.Write(toWrite1); err != os.ErrClosed {
+			t.Errorf("Expected closed error for write after delete, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package bzip2
+
+import (
+	"compress/bzip2"
+	"io"
+
+	"github.com/jonathongardner/fifo"
+)
+
+var _ fifo.ReadCloseReseter = (*Reader)(nil)
+
+// Reader is a bzip2 reader that can be reset back to the start of the stream
+type Reader struct {
+	br io.Reader
+	r  io.ReadSeeker
+}
+
+// NewReader Creates a new bzip2 reader
+// r must be a ReadSeeker, needed to seek to reset the reader
+func NewReader(r io.ReadSeeker) *Reader {
+	return &Reader{br: bzip2.NewReader(r), r: r}
+}
+
+// Read reads data from the bzip2 reader
+func (r *Reader) Read(p []byte) (int, error) {
+	return r.br.Read(p)
+}
+
+// Close closes the underlying reader if it implements io.Closer
+func (r *Reader) Close() error {
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Reset resets the bzip2 reader to the beginning of the stream
+// bzip2 has no reset so a new reader is created
+func (r *Reader) Reset() error {
+	if _, err := r.r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r.br = bzip2.NewReader(r.r)
+	return nil
+}
@@ -0,0 +1,46 @@
+package bzip2
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestBzip2Reader(t *testing.T) {
+	toWrite := []byte("Your String Here")
+	// generated with: printf 'Your String Here' | bzip2 -9
+	f, err := os.Open("testdata/foo.bz2")
+	if err != nil {
+		t.Fatalf("failed to open bzip2 file %v", err)
+	}
+	reader := NewReader(f)
+
+	v1, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal("failed to read bzip2", err)
+	}
+	if string(v1) != string(toWrite) {
+		t.Fatalf("expected %s but got %s", string(toWrite), string(v1))
+	}
+
+	if err := reader.Reset(); err != nil {
+		t.Fatal("failed to reset bzip2", err)
+	}
+
+	v2, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal("failed to read bzip2 after reset", err)
+	}
+	if string(v2) != string(toWrite) {
+		t.Fatalf("expected %s but got %s after reset", string(toWrite), string(v2))
+	}
+
+	if err := reader.Close(); err != nil {
+		t.Fatal("failed to close bzip2", err)
+	}
+	// make sure the file was closed
+	if _, err := f.Read(make([]byte, 1)); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("expected closed error for read from file, got %v", err)
+	}
+}
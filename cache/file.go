@@ -4,15 +4,20 @@ import (
 	"fmt"
 	"io"
 	"os"
+
+	"github.com/jonathongardner/fifo"
 	// log "github.com/sirupsen/logrus"
 )
 
 var ErrAlreadyOpen = fmt.Errorf("file already open")
 
+var _ fifo.ReadCloseReseter = (*File)(nil)
+
 // File is a wrapper around os.File that caches the data read from it
 type File struct {
 	cache *Writer
 	file  *os.File
+	path  string // last path opened, used to open it again on Reset once closed
 }
 
 // Open creates a new file object and opens the file at the given path
@@ -21,7 +26,7 @@ func Open(path string, cache int64) (*File, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &File{file: file, cache: NewWriter(cache)}, nil
+	return &File{file: file, cache: NewWriter(cache), path: path}, nil
 }
 
 // NewFile creates a new file object but does NOT open a file
@@ -36,7 +41,9 @@ func (f *File) Open(path string) error {
 		return ErrAlreadyOpen
 	}
 	var err error
-	f.file, err = os.Open(path)
+	if f.file, err = os.Open(path); err == nil {
+		f.path = path
+	}
 	return err
 }
 
@@ -54,15 +61,31 @@ func (f *File) Read(p []byte) (int, error) {
 	return n1, err1
 }
 
+// Close closes the file, the cache is kept until Reset is called
+func (f *File) Close() error {
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	defer func() { f.file = nil }()
+	return f.file.Close()
+}
+
+// Reset clears the cache and goes back to the start of the file so it can be read
+// again, if the file was closed (or handed off by NewReader) its path is opened again
 func (f *File) Reset() error {
 	if err := f.cache.Reset(); err != nil {
 		return err
 	}
-	if f.file == nil {
+	if f.file != nil {
+		_, err := f.file.Seek(0, io.SeekStart)
+		return err
+	}
+	if f.path == "" {
 		return nil
 	}
-	defer func() { f.file = nil }()
-	return f.file.Close()
+	var err error
+	f.file, err = os.Open(f.path)
+	return err
 }
 
 type ReadSeekCloseCacher interface {
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"io"
 	"os"
 	"testing"
 )
@@ -131,3 +132,73 @@ func TestFile(t *testing.T) {
 		}
 	})
 }
+
+func TestFileClose(t *testing.T) {
+	f, err := Open("testdata/foo", 10)
+	if err != nil {
+		t.Fatalf("expected nil error for open, got %v", err)
+	}
+
+	data := make([]byte, 5)
+	if _, err := f.Read(data); err != nil {
+		t.Fatalf("expected nil error for read from file, got %v", err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Errorf("expected nil error for close, got %v", err)
+	}
+	if err := f.Close(); err != os.ErrClosed {
+		t.Errorf("expected closed error for second close, got %v", err)
+	}
+	if _, err := f.Read(data); err != os.ErrClosed {
+		t.Errorf("expected closed error for read from file, got %v", err)
+	}
+
+	// can open again after close
+	if err := f.Open("testdata/foo"); err != nil {
+		t.Errorf("expected nil error for open after close, got %v", err)
+	}
+}
+
+func TestFileReset(t *testing.T) {
+	exp, err := os.ReadFile("testdata/foo")
+	if err != nil {
+		t.Fatalf("failed to read testdata %v", err)
+	}
+	f, err := Open("testdata/foo", 10)
+	if err != nil {
+		t.Fatalf("expected nil error for open, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatalf("expected nil error for read, got %v", err)
+		}
+		if string(data) != string(exp) {
+			t.Errorf("expected %s, got %s", exp, data)
+		}
+		if f.cache.Size() != int64(len(exp)) {
+			t.Errorf("expected cache to start again after reset, got %d bytes", f.cache.Size())
+		}
+		if err := f.Reset(); err != nil {
+			t.Fatalf("expected nil error for reset, got %v", err)
+		}
+	}
+
+	// a closed file is opened again
+	if err := f.Close(); err != nil {
+		t.Fatalf("expected nil error for close, got %v", err)
+	}
+	if err := f.Reset(); err != nil {
+		t.Fatalf("expected nil error for reset after close, got %v", err)
+	}
+	if data, err := io.ReadAll(f); err != nil || string(data) != string(exp) {
+		t.Errorf("expected %s after close and reset, got %s (%v)", exp, data, err)
+	}
+	f.Close()
+
+	// nothing to go back to if nothing was opened
+	if err := NewFile(10).Reset(); err != nil {
+		t.Errorf("expected nil error for reset, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"encoding/binary"
+)
+
+// TailWriter is a writer that keeps the last max bytes written to it in a ring
+// buffer, for file types that are recognized from their trailer
+type TailWriter struct {
+	size int64
+	max  int64
+	data []byte
+	pos  int // where the next byte goes once data is full
+}
+
+// NewTailWriter creates a new TailWriter
+func NewTailWriter(max int64) *TailWriter {
+	return &TailWriter{max: max, data: make([]byte, 0)}
+}
+
+// Write writes data to the writer
+func (tw *TailWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	tw.size += int64(n)
+	if tw.max <= 0 {
+		return n, nil
+	}
+	if int64(len(p)) > tw.max {
+		p = p[int64(len(p))-tw.max:]
+	}
+	// fill the buffer before wrapping
+	if toFill := int(tw.max) - len(tw.data); toFill > 0 {
+		toFill = min(toFill, len(p))
+		tw.data = append(tw.data, p[:toFill]...)
+		p = p[toFill:]
+	}
+	for len(p) > 0 {
+		copied := copy(tw.data[tw.pos:], p)
+		p = p[copied:]
+		tw.pos = (tw.pos + copied) % len(tw.data)
+	}
+	return n, nil
+}
+
+func (tw *TailWriter) Size() int64 {
+	return tw.size
+}
+
+// Bytes returns the last bytes written in order, it is a copy once the buffer has wrapped
+func (tw *TailWriter) Bytes() []byte {
+	if tw.pos == 0 {
+		return tw.data
+	}
+	return append(append(make([]byte, 0, len(tw.data)), tw.data[tw.pos:]...), tw.data[:tw.pos]...)
+}
+
+// Reset resets the writer
+func (tw *TailWriter) Reset() error {
+	tw.size = 0
+	tw.pos = 0
+	tw.data = tw.data[:0]
+	return nil
+}
+
+// MarshalBinary saves the size and the last bytes in order
+func (tw *TailWriter) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 8+len(tw.data))
+	b = binary.BigEndian.AppendUint64(b, uint64(tw.size))
+	return append(b, tw.Bytes()...), nil
+}
+
+// UnmarshalBinary restores the state saved with MarshalBinary, the writer
+// needs the same max as the one that was saved
+func (tw *TailWriter) UnmarshalBinary(b []byte) error {
+	if len(b) < 8 {
+		return ErrInvalidState
+	}
+	size := int64(binary.BigEndian.Uint64(b))
+	data := b[8:]
+	if size < 0 || int64(len(data)) != min(size, max(tw.max, 0)) {
+		return ErrInvalidState
+	}
+	tw.size = size
+	tw.pos = 0
+	tw.data = append(tw.data[:0], data...)
+	return nil
+}
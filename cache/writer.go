@@ -2,9 +2,13 @@ package cache
 
 import (
 	"bytes"
+	"encoding/binary"
+	"fmt"
 	// log "github.com/sirupsen/logrus"
 )
 
+var ErrInvalidState = fmt.Errorf("invalid cache state")
+
 // Writer is a writer that caches the data in it, up to a certain size
 // io.Copy() or io.MultiWriter() to detect the file type of a stream
 type Writer struct {
@@ -57,6 +61,29 @@ func (mw *Writer) Reset() error {
 	return nil
 }
 
+// MarshalBinary saves the size and the cached data
+func (mw *Writer) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 8+len(mw.data))
+	b = binary.BigEndian.AppendUint64(b, uint64(mw.size))
+	return append(b, mw.data...), nil
+}
+
+// UnmarshalBinary restores the state saved with MarshalBinary, the writer
+// needs the same max as the one that was saved
+func (mw *Writer) UnmarshalBinary(b []byte) error {
+	if len(b) < 8 {
+		return ErrInvalidState
+	}
+	size := int64(binary.BigEndian.Uint64(b))
+	data := b[8:]
+	if size < 0 || int64(len(data)) != min(size, mw.max) {
+		return ErrInvalidState
+	}
+	mw.size = size
+	mw.data = append(mw.data[:0], data...)
+	return nil
+}
+
 type customCache struct {
 	*bytes.Reader
 }
@@ -78,3 +78,89 @@ func TestWriter(t *testing.T) {
 		}
 	})
 }
+
+func TestWriterState(t *testing.T) {
+	w := NewWriter(10)
+	w.Write([]byte("Something cool"))
+	state, err := w.MarshalBinary()
+	if err != nil {
+		t.Fatalf("expected nil error for marshal, got %v", err)
+	}
+
+	restored := NewWriter(10)
+	if err := restored.UnmarshalBinary(state); err != nil {
+		t.Fatalf("expected nil error for unmarshal, got %v", err)
+	}
+	if restored.Size() != 14 || string(restored.Bytes()) != "Something " {
+		t.Errorf("expected 14 and Something, got %d and %s", restored.Size(), string(restored.Bytes()))
+	}
+
+	if err := NewWriter(5).UnmarshalBinary(state); err != ErrInvalidState {
+		t.Errorf("expected invalid state error for smaller max, got %v", err)
+	}
+}
+
+func TestTailWriter(t *testing.T) {
+	t.Run("keeps the end", func(t *testing.T) {
+		w := NewTailWriter(5)
+		w.Write([]byte("Som"))
+		if string(w.Bytes()) != "Som" {
+			t.Errorf("expected Som, got %s", string(w.Bytes()))
+		}
+		w.Write([]byte("ething"))
+		if string(w.Bytes()) != "thing" {
+			t.Errorf("expected thing, got %s", string(w.Bytes()))
+		}
+		w.Write([]byte(" c"))
+		if string(w.Bytes()) != "ing c" {
+			t.Errorf("expected ing c, got %s", string(w.Bytes()))
+		}
+		w.Write([]byte("Something cool"))
+		if string(w.Bytes()) != " cool" {
+			t.Errorf("expected  cool, got %s", string(w.Bytes()))
+		}
+		if w.Size() != 25 {
+			t.Errorf("expected 25, got %d", w.Size())
+		}
+
+		w.Reset()
+		w.Write([]byte("ab"))
+		if w.Size() != 2 || string(w.Bytes()) != "ab" {
+			t.Errorf("expected 2 and ab, got %d and %s", w.Size(), string(w.Bytes()))
+		}
+	})
+
+	t.Run("one byte at a time", func(t *testing.T) {
+		w := NewTailWriter(4)
+		for _, b := range []byte("Something cool") {
+			w.Write([]byte{b})
+		}
+		if string(w.Bytes()) != "cool" {
+			t.Errorf("expected cool, got %s", string(w.Bytes()))
+		}
+	})
+
+	t.Run("state", func(t *testing.T) {
+		w := NewTailWriter(5)
+		w.Write([]byte("Something"))
+		w.Write([]byte(" cool"))
+		state, err := w.MarshalBinary()
+		if err != nil {
+			t.Fatalf("expected nil error for marshal, got %v", err)
+		}
+
+		restored := NewTailWriter(5)
+		if err := restored.UnmarshalBinary(state); err != nil {
+			t.Fatalf("expected nil error for unmarshal, got %v", err)
+		}
+		restored.Write([]byte("er"))
+		w.Write([]byte("er"))
+		if restored.Size() != w.Size() || string(restored.Bytes()) != string(w.Bytes()) {
+			t.Errorf("expected %d and %s, got %d and %s", w.Size(), w.Bytes(), restored.Size(), restored.Bytes())
+		}
+
+		if err := NewTailWriter(4).UnmarshalBinary(state); err != ErrInvalidState {
+			t.Errorf("expected invalid state error for smaller max, got %v", err)
+		}
+	})
+}
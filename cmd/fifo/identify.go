@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+
+	"github.com/jonathongardner/fifo/filetype"
+	"github.com/jonathongardner/fifo/identifiers"
+	"github.com/jonathongardner/fifo/scan"
+)
+
+// record is a line of output
+type record struct {
+	Path string `json:"path"`
+	identifiers.Identifiers
+	Error string `json:"error,omitempty"`
+}
+
+func identify(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("identify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: fifo identify [options] <file|dir|->...\n\nOptions:\n")
+		flags.PrintDefaults()
+	}
+	defaults := identifiers.NewDefultOptions()
+	md5 := flags.Bool("md5", defaults.Md5, "calculate the md5")
+	sha1 := flags.Bool("sha1", defaults.Sha1, "calculate the sha1")
+	sha256 := flags.Bool("sha256", defaults.Sha256, "calculate the sha256")
+	sha512 := flags.Bool("sha512", defaults.Sha512, "calculate the sha512")
+	sha224 := flags.Bool("sha224", defaults.Sha224, "calculate the sha224")
+	sha384 := flags.Bool("sha384", defaults.Sha384, "calculate the sha384")
+	sha512_256 := flags.Bool("sha512_256", defaults.Sha512_256, "calculate the sha512/256")
+	crc32 := flags.Bool("crc32", defaults.Crc32, "calculate the crc32 (IEEE)")
+	crc64 := flags.Bool("crc64", defaults.Crc64, "calculate the crc64 (ECMA)")
+	adler32 := flags.Bool("adler32", defaults.Adler32, "calculate the adler32")
+	fnv32a := flags.Bool("fnv32a", defaults.Fnv32a, "calculate the fnv-1a 32 bit")
+	fnv64a := flags.Bool("fnv64a", defaults.Fnv64a, "calculate the fnv-1a 64 bit")
+	ssdeep := flags.Bool("ssdeep", defaults.Ssdeep, "calculate the ssdeep fuzzy hash")
+	tlsh := flags.Bool("tlsh", defaults.Tlsh, "calculate the tlsh locality sensitive hash")
+	entropy := flags.Bool("entropy", defaults.Entropy, "calculate the entropy")
+	entropyStats := flags.Bool("entropy_stats", defaults.EntropyStats, "run the randomness tests (chi square, mean, monte carlo pi, serial correlation)")
+	classify := flags.Bool("classify", defaults.Classify, "classify the content as plaintext, structured_binary, compressed, encrypted or sparse")
+	ftype := flags.Bool("filetype", defaults.Filetype, "detect the file type")
+	tail := flags.Int64("tail", defaults.TailSize, "bytes kept from the end of the stream to detect the file type of its trailer (i.e. an appended zip), 0 for none")
+	concurrent := flags.Bool("concurrent", defaults.Concurrent, "calculate each hash in its own goroutine")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of files identified at the same time in a directory")
+	format := flags.String("format", "json", "output format: json, jsonl or table")
+	rules := flags.String("rules", "", "JSON or YAML (.yaml, .yml) file of custom file type signatures")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	out, err := newOutput(*format, stdout)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if *rules != "" {
+		if err := filetype.LoadRulesFile(*rules); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	}
+
+	o := identifiers.NewOptions(*md5, *sha1, *sha256, *sha512, *entropy, *ftype, 0).
+		UpdateSha224(*sha224).
+		UpdateSha384(*sha384).
+		UpdateSha512_256(*sha512_256).
+		UpdateCrc32(*crc32).
+		UpdateCrc64(*crc64).
+		UpdateAdler32(*adler32).
+		UpdateFnv32a(*fnv32a).
+		UpdateFnv64a(*fnv64a).
+		UpdateSsdeep(*ssdeep).
+		UpdateTlsh(*tlsh).
+		UpdateEntropyStats(*entropyStats).
+		UpdateClassify(*classify).
+		UpdateTailSize(*tail).
+		UpdateConcurrent(*concurrent)
+	scanOptions := scan.NewOptions(o, *workers)
+	w := o.NewWriter()
+
+	failed := false
+	add := func(r record) error {
+		if r.Error != "" {
+			failed = true
+		}
+		return out.add(r)
+	}
+	for _, path := range flags.Args() {
+		if err := ctx.Err(); err != nil {
+			break
+		}
+		if path == "-" {
+			if err := add(identifyReader(ctx, w, "-", stdin)); err != nil {
+				fmt.Fprintln(stderr, err)
+				return 1
+			}
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			if err := add(record{Path: path, Error: err.Error()}); err != nil {
+				fmt.Fprintln(stderr, err)
+				return 1
+			}
+			continue
+		}
+		if !info.IsDir() {
+			if err := add(identifyFile(ctx, w, path)); err != nil {
+				fmt.Fprintln(stderr, err)
+				return 1
+			}
+			continue
+		}
+		for r := range scanOptions.Dir(ctx, path) {
+			rec := record{Path: r.Path, Identifiers: r.Identifiers}
+			if r.Err != nil {
+				rec.Error = r.Err.Error()
+			}
+			if err := add(rec); err != nil {
+				fmt.Fprintln(stderr, err)
+				return 1
+			}
+		}
+	}
+
+	if err := out.flush(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if err := ctx.Err(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func identifyFile(ctx context.Context, w *identifiers.Writer, path string) record {
+	f, err := os.Open(path)
+	if err != nil {
+		return record{Path: path, Error: err.Error()}
+	}
+	defer f.Close()
+	return identifyReader(ctx, w, path, f)
+}
+
+func identifyReader(ctx context.Context, w *identifiers.Writer, path string, r io.Reader) record {
+	w.Reset()
+	i, err := w.Identify(ctx, r)
+	if err != nil {
+		return record{Path: path, Error: err.Error()}
+	}
+	if path != "-" {
+		i.CheckFilename(path)
+	}
+	return record{Path: path, Identifiers: i}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jonathongardner/fifo/filetype"
+)
+
+func runArgs(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestIdentify(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "tmp")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	foo := filepath.Join(tmpDir, "foo")
+	if err := os.WriteFile(foo, []byte("Something cool"), 0644); err != nil {
+		t.Fatalf("failed to write file %v", err)
+	}
+	fooMd5 := "db5ee56e2cab72f4e46bdd60965bef31"
+
+	t.Run("json", func(t *testing.T) {
+		code, stdout, stderr := runArgs(t, "Something cool", "identify", foo, "-")
+		if code != 0 {
+			t.Fatalf("expected 0 exit code, got %d %s", code, stderr)
+		}
+		records := []record{}
+		if err := json.Unmarshal([]byte(stdout), &records); err != nil {
+			t.Fatalf("failed to parse output %v", err)
+		}
+		if len(records) != 2 {
+			t.Fatalf("expected 2 records, got %d", len(records))
+		}
+		if records[0].Path != foo || records[1].Path != "-" {
+			t.Errorf("expected %s and - paths, got %s and %s", foo, records[0].Path, records[1].Path)
+		}
+		for _, r := range records {
+			if r.Md5 != fooMd5 {
+				t.Errorf("expected %s md5, got %s", fooMd5, r.Md5)
+			}
+		}
+	})
+
+	t.Run("jsonl dir", func(t *testing.T) {
+		code, stdout, stderr := runArgs(t, "", "identify", "-format", "jsonl", "-md5=false", tmpDir)
+		if code != 0 {
+			t.Fatalf("expected 0 exit code, got %d %s", code, stderr)
+		}
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expected 2 lines, got %d", len(lines))
+		}
+		for _, line := range lines {
+			r := record{}
+			if err := json.Unmarshal([]byte(line), &r); err != nil {
+				t.Fatalf("failed to parse line %v", err)
+			}
+			if r.Md5 != "" {
+				t.Errorf("expected no md5, got %s", r.Md5)
+			}
+		}
+	})
+
+	t.Run("checksum flags", func(t *testing.T) {
+		code, stdout, stderr := runArgs(t, "Something cool", "identify", "-format", "jsonl", "-crc32", "-md5=false", "-")
+		if code != 0 {
+			t.Fatalf("expected 0 exit code, got %d %s", code, stderr)
+		}
+		r := record{}
+		if err := json.Unmarshal([]byte(stdout), &r); err != nil {
+			t.Fatalf("failed to parse output %v", err)
+		}
+		if r.Crc32 != "2051db0d" {
+			t.Errorf("expected 2051db0d crc32, got %s", r.Crc32)
+		}
+		if r.Md5 != "" {
+			t.Errorf("expected no md5, got %s", r.Md5)
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		code, stdout, stderr := runArgs(t, "", "identify", "-format", "table", foo)
+		if code != 0 {
+			t.Fatalf("expected 0 exit code, got %d %s", code, stderr)
+		}
+		if !strings.HasPrefix(stdout, "PATH") || !strings.Contains(stdout, fooMd5) {
+			t.Errorf("expected table with header and md5, got %s", stdout)
+		}
+	})
+
+	t.Run("rules", func(t *testing.T) {
+		rules := filepath.Join(tmpDir, "rules.json")
+		content := `{"rules": [{"name": "cool", "mimetype": "text/x-cool", "regex": "^Something cool"}]}`
+		if err := os.WriteFile(rules, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write rules %v", err)
+		}
+		defer os.Remove(rules)
+		defer filetype.UnregisterRule("cool")
+
+		code, stdout, stderr := runArgs(t, "Something cool", "identify", "-format", "jsonl", "-rules", rules, "-")
+		if code != 0 {
+			t.Fatalf("expected 0 exit code, got %d %s", code, stderr)
+		}
+		if !strings.Contains(stdout, `"mimetype":"text/x-cool"`) || !strings.Contains(stdout, `"rule":"cool"`) {
+			t.Errorf("expected file type from the rule, got %s", stdout)
+		}
+
+		yamlRules := filepath.Join(tmpDir, "rules.yaml")
+		content = "rules:\n  - name: cooler\n    mimetype: text/x-cooler\n    regex: ^Written in yaml\n"
+		if err := os.WriteFile(yamlRules, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write rules %v", err)
+		}
+		defer os.Remove(yamlRules)
+		defer filetype.UnregisterRule("cooler")
+
+		code, stdout, stderr = runArgs(t, "Written in yaml", "identify", "-format", "jsonl", "-rules", yamlRules, "-")
+		if code != 0 {
+			t.Fatalf("expected 0 exit code, got %d %s", code, stderr)
+		}
+		if !strings.Contains(stdout, `"mimetype":"text/x-cooler"`) || !strings.Contains(stdout, `"rule":"cooler"`) {
+			t.Errorf("expected file type from the yaml rule, got %s", stdout)
+		}
+
+		code, _, stderr = runArgs(t, "", "identify", "-rules", filepath.Join(tmpDir, "nope"), "-")
+		if code != 2 || !strings.Contains(stderr, "no such file") {
+			t.Errorf("expected 2 exit code with error, got %d %s", code, stderr)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		code, stdout, _ := runArgs(t, "", "identify", "-format", "jsonl", filepath.Join(tmpDir, "nope"))
+		if code != 1 {
+			t.Errorf("expected 1 exit code, got %d", code)
+		}
+		if !strings.Contains(stdout, "no such file") {
+			t.Errorf("expected error in output, got %s", stdout)
+		}
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		if code, _, _ := runArgs(t, ""); code != 2 {
+			t.Errorf("expected 2 exit code for no command, got %d", code)
+		}
+		if code, _, _ := runArgs(t, "", "nope"); code != 2 {
+			t.Errorf("expected 2 exit code for unknown command, got %d", code)
+		}
+		if code, _, _ := runArgs(t, "", "identify"); code != 2 {
+			t.Errorf("expected 2 exit code for no files, got %d", code)
+		}
+		if code, _, _ := runArgs(t, "", "identify", "-format", "xml", foo); code != 2 {
+			t.Errorf("expected 2 exit code for unknown format, got %d", code)
+		}
+	})
+}
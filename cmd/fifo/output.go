@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// output writes the records in one of the formats
+type output interface {
+	add(record) error
+	flush() error
+}
+
+func newOutput(format string, w io.Writer) (output, error) {
+	switch format {
+	case "json":
+		return &jsonOutput{w: w, records: []record{}}, nil
+	case "jsonl":
+		return &jsonlOutput{enc: json.NewEncoder(w)}, nil
+	case "table":
+		return &tableOutput{w: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, expected json, jsonl or table", format)
+}
+
+// jsonOutput writes all the records as a json array once done
+type jsonOutput struct {
+	w       io.Writer
+	records []record
+}
+
+func (o *jsonOutput) add(r record) error {
+	o.records = append(o.records, r)
+	return nil
+}
+
+func (o *jsonOutput) flush() error {
+	enc := json.NewEncoder(o.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(o.records)
+}
+
+// jsonlOutput writes a json object per line as records are added
+type jsonlOutput struct {
+	enc *json.Encoder
+}
+
+func (o *jsonlOutput) add(r record) error {
+	return o.enc.Encode(r)
+}
+
+func (o *jsonlOutput) flush() error {
+	return nil
+}
+
+// tableOutput writes the records as aligned columns
+type tableOutput struct {
+	w      *tabwriter.Writer
+	header bool
+}
+
+func (o *tableOutput) add(r record) error {
+	if !o.header {
+		o.header = true
+		if _, err := fmt.Fprintln(o.w, "PATH\tSIZE\tMIMETYPE\tCLASS\tENTROPY\tMD5\tSHA1\tSHA256\tERROR"); err != nil {
+			return err
+		}
+	}
+	entropy := ""
+	if r.Entropy != 0 {
+		entropy = fmt.Sprintf("%.4f", r.Entropy)
+	}
+	class := ""
+	if r.Class != nil {
+		class = string(r.Class.Class)
+	}
+	_, err := fmt.Fprintf(o.w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		r.Path, r.Size, dash(r.Filetype.Mimetype), dash(class), dash(entropy), dash(r.Md5), dash(r.Sha1), dash(r.Sha256), dash(r.Error))
+	return err
+}
+
+func (o *tableOutput) flush() error {
+	return o.w.Flush()
+}
+
+// dash returns - for empty values so columns line up
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,250 @@
+package cpio
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrHeader = fmt.Errorf("cpio: invalid header")
+
+const (
+	trailer = "TRAILER!!!"
+	maxName = 4096 // size of a name or symlink target
+)
+
+// unix file type bits of the mode
+const (
+	typeMask    = 0170000
+	typeDir     = 0040000
+	typeSymlink = 0120000
+	typeFifo    = 0010000
+	typeChar    = 0020000
+	typeBlock   = 0060000
+	typeSocket  = 0140000
+)
+
+// Header is the header of a file in a cpio archive
+type Header struct {
+	Name     string
+	Linkname string
+	ModTime  time.Time
+	Uid      int
+	Gid      int
+	Mode     fs.FileMode
+	Size     int64
+}
+
+// Reader reads the files of a cpio archive (newc, crc and odc formats)
+// Next moves to the next file and Read reads the data of that file
+type Reader struct {
+	r         io.Reader
+	err       error
+	remaining int64
+	pad       int64
+}
+
+// NewReader Creates a new cpio reader
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+// skip the rest of the current file and its padding
+func (r *Reader) skip() error {
+	if _, err := io.CopyN(io.Discard, r.r, r.remaining+r.pad); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	r.remaining = 0
+	r.pad = 0
+	return nil
+}
+
+// Next advances to the next file in the archive, io.EOF is returned at the end
+func (r *Reader) Next() (*Header, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	hdr, err := r.next()
+	r.err = err
+	return hdr, err
+}
+
+func (r *Reader) next() (*Header, error) {
+	if err := r.skip(); err != nil {
+		return nil, err
+	}
+
+	magic := make([]byte, 6)
+	if _, err := io.ReadFull(r.r, magic); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, ErrHeader
+		}
+		return nil, err
+	}
+
+	var hdr *Header
+	var err error
+	switch string(magic) {
+	case "070701", "070702":
+		hdr, err = r.readNewc()
+	case "070707":
+		hdr, err = r.readOdc()
+	default:
+		return nil, ErrHeader
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hdr.Name == trailer {
+		return nil, io.EOF
+	}
+
+	if hdr.Mode&fs.ModeSymlink != 0 {
+		// the link target is stored as the data
+		if r.remaining > maxName {
+			return nil, ErrHeader
+		}
+		link := make([]byte, r.remaining)
+		if _, err := io.ReadFull(r.r, link); err != nil {
+			return nil, err
+		}
+		r.remaining = 0
+		hdr.Linkname = string(link)
+	}
+	return hdr, nil
+}
+
+// readNewc reads the rest of a newc header, fields are 8 hex characters (unsigned)
+func (r *Reader) readNewc() (*Header, error) {
+	buf := make([]byte, 104)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		return nil, unexpected(err)
+	}
+	fields := make([]int64, 13)
+	for i := range fields {
+		v, err := strconv.ParseUint(string(buf[i*8:i*8+8]), 16, 32)
+		if err != nil {
+			return nil, ErrHeader
+		}
+		fields[i] = int64(v)
+	}
+	// ino, mode, uid, gid, nlink, mtime, filesize, devmajor, devminor, rdevmajor, rdevminor, namesize, check
+	hdr := &Header{
+		Mode:    fileMode(fields[1]),
+		Uid:     int(fields[2]),
+		Gid:     int(fields[3]),
+		ModTime: time.Unix(fields[5], 0),
+		Size:    fields[6],
+	}
+	name, err := r.readName(fields[11], 110)
+	if err != nil {
+		return nil, err
+	}
+	hdr.Name = name
+	r.remaining = hdr.Size
+	r.pad = (4 - hdr.Size%4) % 4
+	return hdr, nil
+}
+
+// readOdc reads the rest of an odc header, fields are octal
+func (r *Reader) readOdc() (*Header, error) {
+	buf := make([]byte, 70)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		return nil, unexpected(err)
+	}
+	// dev, ino, mode, uid, gid, nlink, rdev, mtime, namesize, filesize
+	sizes := []int{6, 6, 6, 6, 6, 6, 6, 11, 6, 11}
+	fields := make([]int64, len(sizes))
+	offset := 0
+	for i, size := range sizes {
+		v, err := strconv.ParseUint(string(buf[offset:offset+size]), 8, 63)
+		if err != nil {
+			return nil, ErrHeader
+		}
+		fields[i] = int64(v)
+		offset += size
+	}
+	hdr := &Header{
+		Mode:    fileMode(fields[2]),
+		Uid:     int(fields[3]),
+		Gid:     int(fields[4]),
+		ModTime: time.Unix(fields[7], 0),
+		Size:    fields[9],
+	}
+	name, err := r.readName(fields[8], 0)
+	if err != nil {
+		return nil, err
+	}
+	hdr.Name = name
+	r.remaining = hdr.Size
+	return hdr, nil
+}
+
+// readName reads the nul terminated name, if headerSize is set the header and
+// name are padded to a multiple of 4 (newc)
+func (r *Reader) readName(size int64, headerSize int64) (string, error) {
+	if size <= 0 || size > maxName {
+		return "", ErrHeader
+	}
+	pad := int64(0)
+	if headerSize > 0 {
+		pad = (4 - (headerSize+size)%4) % 4
+	}
+	buf := make([]byte, size+pad)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		return "", unexpected(err)
+	}
+	return strings.TrimRight(string(buf[:size]), "\x00"), nil
+}
+
+// Read reads from the current file in the archive, returns io.EOF at the end of the file
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	if err == io.EOF && r.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// fileMode converts a unix mode to a fs.FileMode
+func fileMode(mode int64) fs.FileMode {
+	m := fs.FileMode(mode).Perm()
+	switch mode & typeMask {
+	case typeDir:
+		m |= fs.ModeDir
+	case typeSymlink:
+		m |= fs.ModeSymlink
+	case typeFifo:
+		m |= fs.ModeNamedPipe
+	case typeChar:
+		m |= fs.ModeDevice | fs.ModeCharDevice
+	case typeBlock:
+		m |= fs.ModeDevice
+	case typeSocket:
+		m |= fs.ModeSocket
+	}
+	return m
+}
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,135 @@
+package cpio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+type cpioFile struct {
+	name string
+	mode int64
+	data string
+}
+
+func newcArchive(files []cpioFile) []byte {
+	var buf bytes.Buffer
+	files = append(files, cpioFile{name: trailer})
+	for i, f := range files {
+		name := f.name + "\x00"
+		fmt.Fprintf(&buf, "070701%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X",
+			i, f.mode, 0, 0, 1, 0, len(f.data), 0, 0, 0, 0, len(name), 0)
+		buf.WriteString(name)
+		buf.Write(make([]byte, (4-(110+len(name))%4)%4))
+		buf.WriteString(f.data)
+		buf.Write(make([]byte, (4-len(f.data)%4)%4))
+	}
+	return buf.Bytes()
+}
+
+func odcArchive(files []cpioFile) []byte {
+	var buf bytes.Buffer
+	files = append(files, cpioFile{name: trailer})
+	for i, f := range files {
+		name := f.name + "\x00"
+		fmt.Fprintf(&buf, "070707%06o%06o%06o%06o%06o%06o%06o%011o%06o%011o",
+			0, i, f.mode, 0, 0, 1, 0, 0, len(name), len(f.data))
+		buf.WriteString(name)
+		buf.WriteString(f.data)
+	}
+	return buf.Bytes()
+}
+
+func assertArchive(t *testing.T, r *Reader, exp []cpioFile) {
+	t.Helper()
+	for _, e := range exp {
+		hdr, err := r.Next()
+		if err != nil {
+			t.Fatalf("expected nil error for next, got %v", err)
+		}
+		if hdr.Name != e.name {
+			t.Errorf("expected %s name, got %s", e.name, hdr.Name)
+		}
+		if hdr.Mode != fileMode(e.mode) {
+			t.Errorf("expected %v mode, got %v", fileMode(e.mode), hdr.Mode)
+		}
+		if hdr.Mode&fs.ModeSymlink != 0 {
+			if hdr.Linkname != e.data {
+				t.Errorf("expected %s link, got %s", e.data, hdr.Linkname)
+			}
+			continue
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("expected nil error for read, got %v", err)
+		}
+		if string(data) != e.data {
+			t.Errorf("expected %s, got %s", e.data, string(data))
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected EOF for next, got %v", err)
+	}
+}
+
+func TestReader(t *testing.T) {
+	files := []cpioFile{
+		{"foo", 040755, ""},
+		{"foo/bar.txt", 0100644, "Something cool"},
+		{"foo/link", 0120777, "bar.txt"},
+		{"baz", 0100600, "abc"},
+	}
+
+	t.Run("newc", func(t *testing.T) {
+		assertArchive(t, NewReader(bytes.NewReader(newcArchive(files))), files)
+	})
+
+	t.Run("odc", func(t *testing.T) {
+		assertArchive(t, NewReader(bytes.NewReader(odcArchive(files))), files)
+	})
+
+	t.Run("skips unread data", func(t *testing.T) {
+		r := NewReader(bytes.NewReader(newcArchive(files)))
+		for i := 0; i < 3; i++ {
+			if _, err := r.Next(); err != nil {
+				t.Fatalf("expected nil error for next, got %v", err)
+			}
+		}
+		hdr, err := r.Next()
+		if err != nil {
+			t.Fatalf("expected nil error for next, got %v", err)
+		}
+		if hdr.Name != "baz" {
+			t.Errorf("expected baz name, got %s", hdr.Name)
+		}
+	})
+
+	t.Run("invalid sizes", func(t *testing.T) {
+		newc := func(mode, filesize, namesize string) []byte {
+			return []byte("070701" + "00000000" + mode + strings.Repeat("00000000", 4) + filesize + strings.Repeat("00000000", 4) + namesize + "00000000" + "link\x00\x00\x00\x00")
+		}
+		tests := map[string][]byte{
+			"negative symlink size": newc("0000A1FF", "-0000001", "00000005"),
+			"large symlink size":    newc("0000A1FF", "7FFFFFFF", "00000005"),
+			"negative name size":    newc("000081A4", "00000000", "-0000005"),
+			"large name size":       newc("000081A4", "00000000", "FFFFFFFF"),
+			"odc negative size":     []byte("070707" + strings.Repeat("000000", 7) + "00000000000" + "000005" + "-0000000001" + "link\x00"),
+		}
+		for name, content := range tests {
+			r := NewReader(bytes.NewReader(content))
+			if _, err := r.Next(); err != ErrHeader {
+				t.Errorf("expected header error for %s, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("not cpio", func(t *testing.T) {
+		r := NewReader(bytes.NewReader([]byte("Something cool")))
+		if _, err := r.Next(); err != ErrHeader {
+			t.Errorf("expected header error for next, got %v", err)
+		}
+	})
+}
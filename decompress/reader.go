@@ -0,0 +1,131 @@
+package decompress
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+
+	"github.com/jonathongardner/fifo"
+	"github.com/jonathongardner/fifo/bzip2"
+	"github.com/jonathongardner/fifo/filetype"
+	"github.com/jonathongardner/fifo/gzip"
+	"github.com/jonathongardner/fifo/zlib"
+)
+
+// Codec is the compression format picked for a stream
+type Codec string
+
+const (
+	None  Codec = "none"
+	Gzip  Codec = "gzip"
+	Bzip2 Codec = "bzip2"
+	Zlib  Codec = "zlib"
+)
+
+var _ fifo.ReadCloseReseter = (*Reader)(nil)
+
+// Reader reads the decompressed data of a stream, or the stream as is if
+// it isn't compressed (Codec returns None)
+type Reader struct {
+	fifo.ReadCloseReseter
+	codec    Codec
+	filetype filetype.Filetype
+}
+
+// NewReader detects the compression of r and returns a reader for the decompressed data
+// r must be a ReadSeeker, needed to go back to the start after detecting and to reset the reader
+func NewReader(r io.ReadSeeker) (*Reader, error) {
+	ftype, codec, err := Detect(r)
+	if err != nil {
+		return nil, err
+	}
+
+	toReturn := &Reader{codec: codec, filetype: ftype}
+	switch codec {
+	case Gzip:
+		toReturn.ReadCloseReseter, err = gzip.NewReader(r)
+	case Bzip2:
+		toReturn.ReadCloseReseter = bzip2.NewReader(r)
+	case Zlib:
+		toReturn.ReadCloseReseter, err = zlib.NewReader(r)
+	default:
+		toReturn.ReadCloseReseter = fifo.NewReadSeekReseter(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toReturn, nil
+}
+
+// Codec returns the compression format used to decompress the stream
+func (r *Reader) Codec() Codec {
+	return r.codec
+}
+
+// Compressed returns true if the stream was compressed
+func (r *Reader) Compressed() bool {
+	return r.codec != None
+}
+
+// Filetype returns the file type detected for the compressed stream
+func (r *Reader) Filetype() filetype.Filetype {
+	return r.filetype
+}
+
+// Detect reads the start of r to find its file type and compression format
+// r is seeked back to the start after reading
+func Detect(r io.ReadSeeker) (filetype.Filetype, Codec, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return filetype.Filetype{}, None, err
+	}
+	head := make([]byte, filetype.MaxBytesFileDetect())
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return filetype.Filetype{}, None, err
+	}
+	head = head[:n]
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return filetype.Filetype{}, None, err
+	}
+
+	ftype := filetype.NewFiletypeFromBytes(head)
+	// a full head might be the start of a longer stream
+	truncated := uint32(n) == filetype.MaxBytesFileDetect()
+	return ftype, codecFor(ftype, head, truncated), nil
+}
+
+func codecFor(ftype filetype.Filetype, head []byte, truncated bool) Codec {
+	switch ftype.Mimetype {
+	case "application/gzip":
+		return Gzip
+	case "application/x-bzip2":
+		return Bzip2
+	case "application/octet-stream":
+		// zlib has no magic so only try it when nothing else was detected
+		if isZlib(head, truncated) {
+			return Zlib
+		}
+	}
+	return None
+}
+
+// maxInflate is how much of head isZlib inflates before its sure its zlib
+const maxInflate = 1 << 20
+
+// isZlib checks for a zlib header, deflate method with a valid check value and
+// no preset dictionary, https://www.rfc-editor.org/rfc/rfc1950#section-2.2.
+// About 1 in 31 random headers pass that so its confirmed by inflating head
+func isZlib(head []byte, truncated bool) bool {
+	if len(head) < 2 {
+		return false
+	}
+	cmf, flg := head[0], head[1]
+	if cmf&0x0f != 8 || cmf>>4 > 7 || flg&0x20 != 0 {
+		return false
+	}
+	if (uint16(cmf)<<8|uint16(flg))%31 != 0 {
+		return false
+	}
+	_, err := io.CopyN(io.Discard, flate.NewReader(bytes.NewReader(head[2:])), maxInflate)
+	return err == nil || err == io.EOF || (truncated && err == io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,133 @@
+package decompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func zlibCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := zlib.NewWriter(&buf)
+	_, err := writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func assertReader(t *testing.T, r io.ReadSeeker, codec Codec, exp []byte) {
+	t.Helper()
+	reader, err := NewReader(r)
+	if err != nil {
+		t.Fatalf("failed to create new reader %v", err)
+	}
+	if reader.Codec() != codec {
+		t.Errorf("expected %s codec, got %s", codec, reader.Codec())
+	}
+	if reader.Compressed() != (codec != None) {
+		t.Errorf("expected compressed to be %v", codec != None)
+	}
+
+	for i := 0; i < 2; i++ {
+		v, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("failed to read %v", err)
+		}
+		if !bytes.Equal(v, exp) {
+			t.Errorf("expected %s but got %s", string(exp), string(v))
+		}
+		if err := reader.Reset(); err != nil {
+			t.Fatalf("failed to reset %v", err)
+		}
+	}
+
+	if err := reader.Close(); err != nil {
+		t.Fatalf("failed to close %v", err)
+	}
+}
+
+func TestReader(t *testing.T) {
+	toWrite := []byte("Your String Here")
+
+	t.Run("gzip", func(t *testing.T) {
+		gzV, err := gzipCompress(toWrite)
+		if err != nil {
+			t.Fatalf("failed to gz compress data %v", err)
+		}
+		assertReader(t, bytes.NewReader(gzV), Gzip, toWrite)
+	})
+
+	t.Run("zlib", func(t *testing.T) {
+		zlibV, err := zlibCompress(toWrite)
+		if err != nil {
+			t.Fatalf("failed to zlib compress data %v", err)
+		}
+		assertReader(t, bytes.NewReader(zlibV), Zlib, toWrite)
+	})
+
+	t.Run("zlib longer than the head", func(t *testing.T) {
+		// random data so the compressed stream is cut off in the head
+		random := make([]byte, 10000)
+		rand.New(rand.NewSource(1)).Read(random)
+		zlibV, err := zlibCompress(random)
+		if err != nil {
+			t.Fatalf("failed to zlib compress data %v", err)
+		}
+		assertReader(t, bytes.NewReader(zlibV), Zlib, random)
+	})
+
+	t.Run("zlib header", func(t *testing.T) {
+		// text and binary that start with a valid zlib header
+		text := []byte("x^2 + y^2 = z^2\n")
+		assertReader(t, bytes.NewReader(text), None, text)
+		binary := []byte{0x78, 0x9c, 0xff, 0x00, 0x01, 0x02, 0x03}
+		assertReader(t, bytes.NewReader(binary), None, binary)
+	})
+
+	t.Run("bzip2", func(t *testing.T) {
+		// generated with: printf 'Your String Here' | bzip2 -9
+		f, err := os.Open("testdata/foo.bz2")
+		if err != nil {
+			t.Fatalf("failed to open bzip2 file %v", err)
+		}
+		assertReader(t, f, Bzip2, toWrite)
+	})
+
+	t.Run("not compressed", func(t *testing.T) {
+		assertReader(t, bytes.NewReader(toWrite), None, toWrite)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assertReader(t, bytes.NewReader([]byte{}), None, []byte{})
+	})
+
+	t.Run("not compressed after read", func(t *testing.T) {
+		// should start from the beginning even if already read from
+		r := bytes.NewReader(toWrite)
+		r.Read(make([]byte, 4))
+		assertReader(t, r, None, toWrite)
+	})
+}
@@ -0,0 +1,122 @@
+package entropy
+
+import (
+	"math"
+)
+
+// Class is a coarse label for the content of a stream
+type Class string
+
+const (
+	Empty            Class = "empty"
+	Plaintext        Class = "plaintext"
+	StructuredBinary Class = "structured_binary" // executables, databases, images etc
+	Compressed       Class = "compressed"
+	Encrypted        Class = "encrypted" // or random
+	Sparse           Class = "sparse"    // mostly zeros
+)
+
+const (
+	sparseRatio       = 0.9  // zero bytes
+	plaintextRatio    = 0.95 // printable bytes
+	randomEntropy     = 0.97 // normalized entropy
+	compressedEntropy = 0.85 // normalized entropy
+	randomChiSquare   = 0.001
+	confidentSize     = 4096 // high entropy data smaller than this is less confident
+	utf8Slack         = 6    // continuation bytes a window can cut off at each end
+)
+
+// Classification is the class of a stream with a confidence between 0 and 1
+type Classification struct {
+	Class      Class   `json:"class"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Classify labels the data written using the byte frequency
+func (w *Writer) Classify() Classification {
+	return classify(&w.frequency, w.count)
+}
+
+func classify(frequency *[256]uint64, count uint64) Classification {
+	if count == 0 {
+		return Classification{Class: Empty, Confidence: 1}
+	}
+	total := float64(count)
+
+	zero := float64(frequency[0]) / total
+	if zero >= sparseRatio {
+		return Classification{Class: Sparse, Confidence: confidence(zero, sparseRatio, 1)}
+	}
+
+	printable := float64(frequency['\t'] + frequency['\n'] + frequency['\r'])
+	for b := 0x20; b < 0x7f; b++ {
+		printable += float64(frequency[b])
+	}
+	printable += float64(utf8Printable(frequency))
+	printable /= total
+	if printable >= plaintextRatio {
+		return Classification{Class: Plaintext, Confidence: confidence(printable, plaintextRatio, 1)}
+	}
+
+	// normalize so small streams can be compared, they cant use all 256 values
+	ent := 0.0
+	if count > 1 {
+		ent = entropy(frequency, count) / math.Log2(float64(min(count, 256)))
+	}
+	// not much can be told about high entropy data when its small
+	sizeFactor := min(1, total/confidentSize)
+	switch {
+	case ent >= randomEntropy:
+		expected := total / 256
+		chiSquare := 0.0
+		for _, f := range frequency {
+			d := float64(f) - expected
+			chiSquare += d * d / expected
+		}
+		// compressed data has high entropy but its bytes are less uniform than random data
+		class := Encrypted
+		if chiSquareProbability(chiSquare, 255) < randomChiSquare {
+			class = Compressed
+		}
+		return Classification{Class: class, Confidence: confidence(ent, randomEntropy, 1) * sizeFactor}
+	case ent >= compressedEntropy:
+		return Classification{Class: Compressed, Confidence: confidence(ent, compressedEntropy, randomEntropy) * sizeFactor}
+	}
+	return Classification{Class: StructuredBinary, Confidence: confidence(compressedEntropy-ent, 0, compressedEntropy)}
+}
+
+// utf8Printable returns the number of bytes in multibyte UTF-8 sequences (non ASCII text)
+// if the frequency looks like valid UTF-8, the lead bytes say how many continuation
+// bytes there should be and bytes that are never in UTF-8 mean its not
+func utf8Printable(frequency *[256]uint64) uint64 {
+	var leads, continuations, expected uint64
+	for b := 0x80; b < 0x100; b++ {
+		f := frequency[b]
+		switch {
+		case b < 0xc0:
+			continuations += f
+		case b < 0xc2 || b > 0xf4:
+			if f > 0 {
+				return 0
+			}
+		case b < 0xe0:
+			leads += f
+			expected += f
+		case b < 0xf0:
+			leads += f
+			expected += 2 * f
+		default:
+			leads += f
+			expected += 3 * f
+		}
+	}
+	if continuations+utf8Slack < expected || continuations > expected+utf8Slack {
+		return 0
+	}
+	return leads + continuations
+}
+
+// confidence maps v between lo and hi to 0.5 and 1, the further past the threshold the more confident
+func confidence(v, lo, hi float64) float64 {
+	return 0.5 + 0.5*min(1, max(0, (v-lo)/(hi-lo)))
+}
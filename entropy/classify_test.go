@@ -0,0 +1,117 @@
+package entropy
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func words(r *rand.Rand, n int) []byte {
+	list := strings.Fields("the quick brown fox jumps over lazy dog cool beans this is awesome really nice foo who boo")
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		buf.WriteString(list[r.Intn(len(list))])
+		if i%12 == 11 {
+			buf.WriteString(".\n")
+		} else {
+			buf.WriteByte(' ')
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestClassify(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	text := words(r, 50000)
+
+	random := make([]byte, 1<<18)
+	r.Read(random)
+
+	var compressed bytes.Buffer
+	fw, _ := flate.NewWriter(&compressed, flate.DefaultCompression)
+	fw.Write(words(r, 100000))
+	fw.Close()
+
+	structured := []byte{}
+	for i := 0; i < 20000; i++ {
+		structured = binary.LittleEndian.AppendUint32(structured, uint32(i*7))
+		structured = binary.LittleEndian.AppendUint16(structured, uint16(r.Intn(4)))
+		structured = append(structured, "ID"...)
+	}
+
+	sparse := make([]byte, 10000)
+	copy(sparse[5000:], random[:100])
+
+	tests := []struct {
+		name  string
+		data  []byte
+		class Class
+	}{
+		{"empty", []byte{}, Empty},
+		{"plaintext", text, Plaintext},
+		{"random", random, Encrypted},
+		{"compressed", compressed.Bytes(), Compressed},
+		{"structured", structured, StructuredBinary},
+		{"sparse", sparse, Sparse},
+		{"single byte", []byte{0xff}, StructuredBinary},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := NewWriter()
+			w.Write(test.data)
+			c := w.Classify()
+			if c.Class != test.class {
+				t.Errorf("expected %v, got %v (%v)", test.class, c.Class, c.Confidence)
+			}
+			if c.Confidence < 0 || c.Confidence > 1 {
+				t.Errorf("expected confidence between 0 and 1, got %v", c.Confidence)
+			}
+		})
+	}
+
+	t.Run("non ascii text", func(t *testing.T) {
+		tests := map[string]string{
+			"cyrillic": "съешь же ещё этих мягких французских булок, да выпей чаю.\n",
+			"cjk":      "我能吞下玻璃而不伤身体。私はガラスを食べられます。\n",
+		}
+		for name, line := range tests {
+			w := NewWriter()
+			w.Write([]byte(strings.Repeat(line, 500)))
+			if c := w.Classify(); c.Class != Plaintext {
+				t.Errorf("expected %v for %s, got %+v", Plaintext, name, c)
+			}
+		}
+
+		// latin-1 isnt valid UTF-8
+		w := NewWriter()
+		w.Write(bytes.Repeat([]byte("caf\xe9 cr\xe8me br\xfbl\xe9e "), 500))
+		if c := w.Classify(); c.Class == Plaintext {
+			t.Errorf("expected latin-1 not to be %v", Plaintext)
+		}
+	})
+
+	t.Run("small random", func(t *testing.T) {
+		w := NewWriter()
+		w.Write(random[:300])
+		if c := w.Classify(); c.Confidence >= 0.5 {
+			t.Errorf("expected low confidence for small data, got %+v", c)
+		}
+	})
+
+	t.Run("windows", func(t *testing.T) {
+		w, _ := NewWindowWriter(4096, 4096)
+		w.Write(text[:8192])
+		w.Write(random[:8192])
+		p := w.Profile()
+		exp := []Class{Plaintext, Plaintext, Encrypted, Encrypted}
+		for i, win := range p.Windows {
+			if win.Class != exp[i] {
+				t.Errorf("expected window %d to be %v, got %v", i, exp[i], win.Class)
+			}
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package entropy
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var (
+	ErrNotContiguous = fmt.Errorf("segments are not contiguous")
+	ErrStatsMismatch = fmt.Errorf("segments dont all track stats")
+	ErrInvalidState  = fmt.Errorf("invalid entropy state")
+)
+
+const (
+	stateMagic   = "ent"
+	stateVersion = 2
+	stateLen     = len(stateMagic) + 1 + 1 + 8*2 + 256*8 + 2 + 8 + monteN*2 + 3 + 8*2
+)
+
+// NewSegmentWriter creates a writer for the data of a bigger stream starting at offset,
+// the segment writers can be merged (in order) to get the same result as writing
+// the whole stream to one writer
+func NewSegmentWriter(offset uint64) *Writer {
+	w := NewWriter()
+	w.offset = offset
+	return w
+}
+
+// NewStatsSegmentWriter creates a segment writer that tracks stats like NewStatsWriter
+func NewStatsSegmentWriter(offset uint64) *Writer {
+	w := NewSegmentWriter(offset)
+	w.stats = true
+	w.monte.headLen = int((monteN - offset%monteN) % monteN)
+	return w
+}
+
+// Offset returns where the data starts in the stream, 0 unless its a segment writer
+func (w *Writer) Offset() uint64 {
+	return w.offset
+}
+
+// Size returns the number of bytes written
+func (w *Writer) Size() uint64 {
+	return w.count
+}
+
+// Histogram returns the number of times each byte has been written
+func (w *Writer) Histogram() [256]uint64 {
+	return w.frequency
+}
+
+// Merge adds the data of other to the writer, other has to be the segment that
+// starts right where the writer ends, both or neither have to track stats
+func (w *Writer) Merge(other *Writer) error {
+	if other.offset != w.offset+w.count {
+		return fmt.Errorf("%w: expected offset %d, got %d", ErrNotContiguous, w.offset+w.count, other.offset)
+	}
+	if other.stats != w.stats {
+		return ErrStatsMismatch
+	}
+	if other.count == 0 {
+		return nil
+	}
+
+	for i, f := range other.frequency {
+		w.frequency[i] += f
+	}
+	w.count += other.count
+	if !w.stats {
+		return nil
+	}
+	count := w.count - other.count
+
+	if count == 0 {
+		w.serial = other.serial
+	} else {
+		w.serial.sumProd += other.serial.sumProd + uint64(w.serial.last)*uint64(other.serial.first)
+		w.serial.last = other.serial.last
+	}
+
+	// the head of other finishes the point the writer was in the middle of
+	w.monte.write(other.monte.head[:other.monte.headN])
+	w.monte.inside += other.monte.inside
+	w.monte.samples += other.monte.samples
+	if other.monte.headN == other.monte.headLen {
+		w.monte.buf = other.monte.buf
+		w.monte.n = other.monte.n
+	}
+	return nil
+}
+
+// MarshalBinary saves the state of the writer
+func (w *Writer) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, stateLen)
+	b = append(b, stateMagic...)
+	b = append(b, stateVersion)
+	if w.stats {
+		b = append(b, 1)
+	} else {
+		b = append(b, 0)
+	}
+	b = binary.BigEndian.AppendUint64(b, w.count)
+	b = binary.BigEndian.AppendUint64(b, w.offset)
+	for _, f := range w.frequency {
+		b = binary.BigEndian.AppendUint64(b, f)
+	}
+	b = append(b, w.serial.first, w.serial.last)
+	b = binary.BigEndian.AppendUint64(b, w.serial.sumProd)
+	b = append(b, w.monte.head[:]...)
+	b = append(b, w.monte.buf[:]...)
+	b = append(b, byte(w.monte.headN), byte(w.monte.headLen), byte(w.monte.n))
+	b = binary.BigEndian.AppendUint64(b, w.monte.inside)
+	b = binary.BigEndian.AppendUint64(b, w.monte.samples)
+	return b, nil
+}
+
+// UnmarshalBinary restores the state of the writer saved with MarshalBinary
+func (w *Writer) UnmarshalBinary(b []byte) error {
+	if len(b) != stateLen || string(b[:len(stateMagic)]) != stateMagic || b[len(stateMagic)] != stateVersion {
+		return ErrInvalidState
+	}
+	b = b[len(stateMagic)+1:]
+	if b[0] > 1 {
+		return ErrInvalidState
+	}
+	stats := b[0] == 1
+	b = b[1:]
+	next := func() uint64 {
+		v := binary.BigEndian.Uint64(b)
+		b = b[8:]
+		return v
+	}
+
+	s := Writer{stats: stats}
+	s.count = next()
+	s.offset = next()
+	var total uint64
+	for i := range s.frequency {
+		s.frequency[i] = next()
+		total += s.frequency[i]
+	}
+	s.serial.first, s.serial.last = b[0], b[1]
+	b = b[2:]
+	s.serial.sumProd = next()
+	copy(s.monte.head[:], b)
+	copy(s.monte.buf[:], b[monteN:])
+	b = b[monteN*2:]
+	s.monte.headN, s.monte.headLen, s.monte.n = int(b[0]), int(b[1]), int(b[2])
+	b = b[3:]
+	s.monte.inside = next()
+	s.monte.samples = next()
+
+	if total != s.count || s.monte.headN > s.monte.headLen || s.monte.headLen >= monteN || s.monte.n >= monteN || s.monte.inside > s.monte.samples {
+		return ErrInvalidState
+	}
+	*w = s
+	return nil
+}
+
+// ReadAt calculates the entropy of the size bytes of r, reading segments at the same time,
+// the writer returned tracks stats
+func ReadAt(r io.ReaderAt, size int64, segments int) (*Writer, error) {
+	segments = max(1, segments)
+	segmentSize := (size + int64(segments) - 1) / int64(segments)
+
+	writers := make([]*Writer, segments)
+	errs := make([]error, segments)
+	var wg sync.WaitGroup
+	for i := range writers {
+		offset := min(int64(i)*segmentSize, size)
+		writers[i] = NewStatsSegmentWriter(uint64(offset))
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = io.Copy(writers[i], io.NewSectionReader(r, offset, min(segmentSize, size-offset)))
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	w := writers[0]
+	for _, other := range writers[1:] {
+		if err := w.Merge(other); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
@@ -0,0 +1,124 @@
+package entropy
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	data := make([]byte, 100000)
+	r.Read(data)
+	copy(data[20000:], words(r, 5000))
+
+	single := NewStatsWriter()
+	single.Write(data)
+	exp := single.Stats()
+
+	t.Run("segments", func(t *testing.T) {
+		// try cuts that do and dont line up with the monte carlo points, including tiny segments
+		for _, cuts := range [][]int{
+			{50000},
+			{1, 2, 3, 4, 5, 6, 7, 13},
+			{6, 12, 60000},
+			{33333, 66666},
+			{99999},
+		} {
+			cuts = append(cuts, len(data))
+			w := NewStatsSegmentWriter(0)
+			w.Write(data[:cuts[0]])
+			for i := 1; i < len(cuts); i++ {
+				segment := NewStatsSegmentWriter(uint64(cuts[i-1]))
+				segment.Write(data[cuts[i-1]:cuts[i]])
+				if err := w.Merge(segment); err != nil {
+					t.Fatalf("expected nil error for merge, got %v", err)
+				}
+			}
+			if w.Stats() != exp {
+				t.Errorf("expected %+v for %v, got %+v", exp, cuts, w.Stats())
+			}
+			if w.Histogram() != single.Histogram() {
+				t.Errorf("expected histograms to match for %v", cuts)
+			}
+		}
+	})
+
+	t.Run("not contiguous", func(t *testing.T) {
+		w := NewStatsWriter()
+		w.Write(data[:10])
+		if err := w.Merge(NewStatsSegmentWriter(11)); !errors.Is(err, ErrNotContiguous) {
+			t.Errorf("expected not contiguous error, got %v", err)
+		}
+	})
+
+	t.Run("without stats", func(t *testing.T) {
+		w := NewSegmentWriter(0)
+		w.Write(data[:50000])
+		segment := NewSegmentWriter(50000)
+		segment.Write(data[50000:])
+		if err := w.Merge(segment); err != nil {
+			t.Fatalf("expected nil error for merge, got %v", err)
+		}
+		if w.Histogram() != single.Histogram() || w.Entropy() != single.Entropy() {
+			t.Errorf("expected histograms to match")
+		}
+
+		if err := NewStatsWriter().Merge(NewSegmentWriter(0)); err != ErrStatsMismatch {
+			t.Errorf("expected stats mismatch error, got %v", err)
+		}
+	})
+
+	t.Run("read at", func(t *testing.T) {
+		for _, segments := range []int{0, 1, 7, 64} {
+			w, err := ReadAt(bytes.NewReader(data), int64(len(data)), segments)
+			if err != nil {
+				t.Fatalf("expected nil error for read at, got %v", err)
+			}
+			if w.Stats() != exp {
+				t.Errorf("expected %+v for %d segments, got %+v", exp, segments, w.Stats())
+			}
+		}
+		w, _ := ReadAt(bytes.NewReader(data[:3]), 3, 8)
+		if w.Size() != 3 {
+			t.Errorf("expected size 3, got %d", w.Size())
+		}
+	})
+
+	t.Run("marshal", func(t *testing.T) {
+		w := NewStatsSegmentWriter(3)
+		w.Write(data[3:50001])
+		state, err := w.MarshalBinary()
+		if err != nil {
+			t.Fatalf("expected nil error for marshal, got %v", err)
+		}
+
+		restored := NewStatsWriter()
+		if err := restored.UnmarshalBinary(state); err != nil {
+			t.Fatalf("expected nil error for unmarshal, got %v", err)
+		}
+		if *restored != *w {
+			t.Errorf("expected restored writer to match")
+		}
+		restored.Write(data[50001:])
+
+		first := NewStatsWriter()
+		first.Write(data[:3])
+		if err := first.Merge(restored); err != nil {
+			t.Fatalf("expected nil error for merge, got %v", err)
+		}
+		if first.Stats() != exp {
+			t.Errorf("expected %+v, got %+v", exp, first.Stats())
+		}
+
+		if err := restored.UnmarshalBinary(state[:10]); err != ErrInvalidState {
+			t.Errorf("expected invalid state error for short state, got %v", err)
+		}
+		// count doesnt match the histogram
+		state[len(stateMagic)+1]++
+		if err := restored.UnmarshalBinary(state); err != ErrInvalidState {
+			t.Errorf("expected invalid state error for bad counts, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package entropy
+
+import (
+	"math"
+)
+
+// Randomness tests from ent (https://www.fourmilab.ch/random/)
+
+const (
+	monteN = 6 // bytes per monte carlo point, 3 for x and 3 for y
+)
+
+var inCircle = math.Pow(math.Pow(256, monteN/2)-1, 2)
+
+// Stats is the result of the randomness tests, values are 0 if there isnt enough data to calculate them
+// MonteCarloPi, MonteCarloPiError and SerialCorrelation are only set by a stats writer
+type Stats struct {
+	Size      uint64  `json:"size"`
+	Entropy   float64 `json:"entropy"`
+	ChiSquare float64 `json:"chi_square"`
+	// probability a truly random stream would exceed ChiSquare, close to 0 or 1 is not random
+	ChiSquareProbability float64 `json:"chi_square_probability"`
+	Mean                 float64 `json:"mean"` // 127.5 for random data
+	MonteCarloPi         float64 `json:"monte_carlo_pi"`
+	MonteCarloPiError    float64 `json:"monte_carlo_pi_error"` // percent off from pi
+	SerialCorrelation    float64 `json:"serial_correlation"`   // 0 for random data, -1 to 1
+}
+
+// serial keeps track of consecutive bytes for the serial correlation
+type serial struct {
+	first   byte
+	last    byte
+	sumProd uint64 // sum of each byte multiplied by the one before it
+}
+
+func (s *serial) write(p []byte, count uint64) {
+	for _, b := range p {
+		if count == 0 {
+			s.first = b
+		} else {
+			s.sumProd += uint64(s.last) * uint64(b)
+		}
+		s.last = b
+		count++
+	}
+}
+
+// monte keeps track of the monte carlo points, each 6 bytes is a point in a square
+// a segment that doesnt start on a point keeps the bytes until the next one in head
+// so they can be combined with the previous segment when merging
+type monte struct {
+	head    [monteN]byte
+	headN   int
+	headLen int
+	buf     [monteN]byte
+	n       int
+	inside  uint64
+	samples uint64
+}
+
+func (m *monte) write(p []byte) {
+	for _, b := range p {
+		if m.headN < m.headLen {
+			m.head[m.headN] = b
+			m.headN++
+			continue
+		}
+		m.buf[m.n] = b
+		m.n++
+		if m.n < monteN {
+			continue
+		}
+		m.n = 0
+		var x, y float64
+		for i := 0; i < monteN/2; i++ {
+			x = x*256 + float64(m.buf[i])
+			y = y*256 + float64(m.buf[monteN/2+i])
+		}
+		m.samples++
+		if x*x+y*y <= inCircle {
+			m.inside++
+		}
+	}
+}
+
+// Stats returns the randomness tests for the data written
+func (w *Writer) Stats() Stats {
+	s := Stats{Size: w.count, Entropy: w.Entropy()}
+	if w.count == 0 {
+		return s
+	}
+	total := float64(w.count)
+
+	expected := total / 256
+	var sum, sumSquares float64
+	for i, f := range w.frequency {
+		d := float64(f) - expected
+		s.ChiSquare += d * d / expected
+		sum += float64(i) * float64(f)
+		sumSquares += float64(i) * float64(i) * float64(f)
+	}
+	s.ChiSquareProbability = chiSquareProbability(s.ChiSquare, 255)
+	s.Mean = sum / total
+
+	if !w.stats {
+		return s
+	}
+	if w.monte.samples > 0 {
+		s.MonteCarloPi = 4 * float64(w.monte.inside) / float64(w.monte.samples)
+		s.MonteCarloPiError = 100 * math.Abs(math.Pi-s.MonteCarloPi) / math.Pi
+	}
+
+	// the last byte wraps around to the first
+	sumProd := float64(w.serial.sumProd) + float64(w.serial.last)*float64(w.serial.first)
+	if d := total*sumSquares - sum*sum; d != 0 {
+		s.SerialCorrelation = (total*sumProd - sum*sum) / d
+	}
+	return s
+}
+
+// chiSquareProbability is the probability of a chi square of at least x with df degrees of freedom
+func chiSquareProbability(x float64, df int) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return gammaQ(float64(df)/2, x/2)
+}
+
+// gammaQ is the regularized upper incomplete gamma function
+func gammaQ(a, x float64) float64 {
+	const (
+		maxIterations = 1000
+		epsilon       = 1e-15
+		tiny          = 1e-300
+	)
+	lg, _ := math.Lgamma(a)
+	front := math.Exp(-x + a*math.Log(x) - lg)
+	if x < a+1 {
+		// series for the lower gamma
+		sum, term := 1/a, 1/a
+		for n := 1; n < maxIterations; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*epsilon {
+				break
+			}
+		}
+		return max(0, 1-sum*front)
+	}
+	// continued fraction (modified lentz)
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < maxIterations; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return front * h
+}
@@ -0,0 +1,117 @@
+package entropy
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		if s := NewStatsWriter().Stats(); s != (Stats{}) {
+			t.Errorf("expected empty stats, got %+v", s)
+		}
+	})
+
+	t.Run("uniform", func(t *testing.T) {
+		x := []byte{}
+		for i := 0; i < 256; i++ {
+			x = append(x, byte(i))
+		}
+		w := NewStatsWriter()
+		w.Write(bytes.Repeat(x, 4))
+		s := w.Stats()
+		if s.Size != 1024 || s.Entropy != 8 || s.ChiSquare != 0 || s.ChiSquareProbability != 1 || s.Mean != 127.5 {
+			t.Errorf("unexpected stats %+v", s)
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		data := make([]byte, 1<<20)
+		rand.New(rand.NewSource(1)).Read(data)
+		w := NewStatsWriter()
+		// write in odd sizes to make sure monte carlo and serial dont depend on the writes
+		for i := 0; i < len(data); i += 1001 {
+			w.Write(data[i:min(i+1001, len(data))])
+		}
+		s := w.Stats()
+		if s.Entropy < 7.99 {
+			t.Errorf("expected entropy close to 8, got %v", s.Entropy)
+		}
+		if s.ChiSquareProbability < 0.01 || s.ChiSquareProbability > 0.99 {
+			t.Errorf("expected chi square probability to look random, got %v (%v)", s.ChiSquareProbability, s.ChiSquare)
+		}
+		if math.Abs(s.Mean-127.5) > 0.5 {
+			t.Errorf("expected mean close to 127.5, got %v", s.Mean)
+		}
+		if s.MonteCarloPiError > 1 {
+			t.Errorf("expected monte carlo pi close to pi, got %v", s.MonteCarloPi)
+		}
+		if math.Abs(s.SerialCorrelation) > 0.01 {
+			t.Errorf("expected serial correlation close to 0, got %v", s.SerialCorrelation)
+		}
+
+		single := NewStatsWriter()
+		single.Write(data)
+		if single.Stats() != s {
+			t.Errorf("expected %+v, got %+v", single.Stats(), s)
+		}
+
+		w.Reset()
+		w.Write(data)
+		if w.Stats() != s {
+			t.Errorf("expected %+v after reset, got %+v", s, w.Stats())
+		}
+	})
+
+	t.Run("serial correlation", func(t *testing.T) {
+		w := NewStatsWriter()
+		w.Write(bytes.Repeat([]byte{0, 255}, 100))
+		if s := w.Stats(); s.SerialCorrelation != -1 {
+			t.Errorf("expected -1, got %v", s.SerialCorrelation)
+		}
+
+		w.Reset()
+		w.Write(bytes.Repeat([]byte("a"), 100))
+		if s := w.Stats(); s.SerialCorrelation != 0 || s.MonteCarloPi == 0 {
+			t.Errorf("expected 0 serial correlation and monte carlo pi, got %+v", s)
+		}
+	})
+
+	t.Run("without stats", func(t *testing.T) {
+		data := bytes.Repeat([]byte{0, 255, 7}, 100)
+		w := NewWriter()
+		w.Write(data)
+		if w.serial != (serial{}) || w.monte != (monte{}) {
+			t.Errorf("expected no serial or monte carlo state, got %+v %+v", w.serial, w.monte)
+		}
+
+		stats := NewStatsWriter()
+		stats.Write(data)
+		exp := stats.Stats()
+		exp.MonteCarloPi, exp.MonteCarloPiError, exp.SerialCorrelation = 0, 0, 0
+		if s := w.Stats(); s != exp {
+			t.Errorf("expected %+v, got %+v", exp, s)
+		}
+
+		stats.Reset()
+		stats.Write(data)
+		if s := stats.Stats(); s.SerialCorrelation == 0 {
+			t.Errorf("expected stats after reset, got %+v", s)
+		}
+	})
+
+	t.Run("chi square probability", func(t *testing.T) {
+		// Q(1, 1) = e^-1
+		if p := chiSquareProbability(2, 2); math.Abs(p-math.Exp(-1)) > 1e-12 {
+			t.Errorf("expected %v, got %v", math.Exp(-1), p)
+		}
+		if p := chiSquareProbability(255, 255); math.Abs(p-0.49) > 0.02 {
+			t.Errorf("expected about 0.49, got %v", p)
+		}
+		if p := chiSquareProbability(1000, 255); p > 1e-6 {
+			t.Errorf("expected close to 0, got %v", p)
+		}
+	})
+}
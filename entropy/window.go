@@ -0,0 +1,137 @@
+package entropy
+
+import (
+	"fmt"
+)
+
+var ErrInvalidWindow = fmt.Errorf("invalid window")
+
+// Window is the entropy and class of the block of data starting at Offset
+type Window struct {
+	Offset  uint64  `json:"offset"`
+	Size    uint64  `json:"size"`
+	Entropy float64 `json:"entropy"`
+	Classification
+}
+
+// Region is a range of data made up of consecutive (or overlapping) windows,
+// Entropy is the average entropy of the windows
+type Region struct {
+	Offset  uint64  `json:"offset"`
+	Size    uint64  `json:"size"`
+	Entropy float64 `json:"entropy"`
+}
+
+// Profile is the entropy of each block of a stream
+type Profile struct {
+	BlockSize int      `json:"block_size"`
+	Step      int      `json:"step"`
+	Size      uint64   `json:"size"`
+	Windows   []Window `json:"windows"`
+}
+
+// WindowWriter is an io.Writer like object for calculating the entropy of every
+// block of blockSize bytes, moving step bytes at a time
+type WindowWriter struct {
+	blockSize int
+	step      int
+	ring      []byte
+	window    Writer
+	count     uint64
+	windows   []Window
+}
+
+// NewWindowWriter creates a new window writer, step must be between 1 and blockSize
+// (use blockSize for blocks that dont overlap)
+func NewWindowWriter(blockSize, step int) (*WindowWriter, error) {
+	if blockSize <= 0 || step <= 0 || step > blockSize {
+		return nil, fmt.Errorf("%w: block size %d, step %d", ErrInvalidWindow, blockSize, step)
+	}
+	return &WindowWriter{blockSize: blockSize, step: step, ring: make([]byte, blockSize)}, nil
+}
+
+func (w *WindowWriter) Write(p []byte) (n int, err error) {
+	bs := uint64(w.blockSize)
+	for _, b := range p {
+		i := w.count % bs
+		if w.count >= bs {
+			// drop the byte leaving the window
+			w.window.frequency[w.ring[i]]--
+			w.window.count--
+		}
+		w.ring[i] = b
+		w.window.frequency[b]++
+		w.window.count++
+		w.count++
+
+		if w.count >= bs && (w.count-bs)%uint64(w.step) == 0 {
+			w.windows = append(w.windows, w.current())
+		}
+	}
+	return len(p), nil
+}
+
+// Profile returns the windows written so far, if the end of the data isnt
+// covered by a window the last blockSize bytes (or all of them if there are
+// less) are added as a final window
+func (w *WindowWriter) Profile() Profile {
+	p := Profile{BlockSize: w.blockSize, Step: w.step, Size: w.count}
+	p.Windows = append([]Window{}, w.windows...)
+	if w.count == 0 {
+		return p
+	}
+	if l := len(p.Windows); l == 0 || p.Windows[l-1].Offset+p.Windows[l-1].Size < w.count {
+		p.Windows = append(p.Windows, w.current())
+	}
+	return p
+}
+
+// current is the window of the last blockSize bytes
+func (w *WindowWriter) current() Window {
+	return Window{
+		Offset:         w.count - w.window.count,
+		Size:           w.window.count,
+		Entropy:        w.window.Entropy(),
+		Classification: w.window.Classify(),
+	}
+}
+
+func (w *WindowWriter) Reset() {
+	w.window.Reset()
+	w.count = 0
+	w.windows = nil
+}
+
+// HighRegions returns the regions where every window has an entropy of at least threshold
+func (p Profile) HighRegions(threshold float64) []Region {
+	return p.regions(func(e float64) bool { return e >= threshold })
+}
+
+// LowRegions returns the regions where every window has an entropy of at most threshold
+func (p Profile) LowRegions(threshold float64) []Region {
+	return p.regions(func(e float64) bool { return e <= threshold })
+}
+
+func (p Profile) regions(match func(float64) bool) []Region {
+	regions := []Region{}
+	var current *Region
+	var count int
+	for _, win := range p.Windows {
+		if !match(win.Entropy) {
+			current = nil
+			continue
+		}
+		end := win.Offset + win.Size
+		if current != nil && win.Offset <= current.Offset+current.Size {
+			// extend the region, entropy is the running average of the windows
+			current.Size = max(current.Size, end-current.Offset)
+			count++
+			current.Entropy += (win.Entropy - current.Entropy) / float64(count)
+			continue
+		}
+		regions = append(regions, Region{Offset: win.Offset, Size: win.Size, Entropy: win.Entropy})
+		current = &regions[len(regions)-1]
+		count = 1
+	}
+	return regions
+}
@@ -0,0 +1,101 @@
+package entropy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestWindowWriter(t *testing.T) {
+	random := make([]byte, 4096)
+	rand.New(rand.NewSource(1)).Read(random)
+	// zeros, random, text
+	data := append(make([]byte, 4096), random...)
+	data = append(data, bytes.Repeat([]byte("abcd"), 1024)...)
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, v := range [][2]int{{0, 1}, {10, 0}, {10, 11}} {
+			if _, err := NewWindowWriter(v[0], v[1]); !errors.Is(err, ErrInvalidWindow) {
+				t.Errorf("expected invalid window error for %v, got %v", v, err)
+			}
+		}
+	})
+
+	t.Run("windows", func(t *testing.T) {
+		w, _ := NewWindowWriter(1024, 512)
+		// write in odd sizes to make sure windows dont depend on the writes
+		for i := 0; i < len(data); i += 100 {
+			w.Write(data[i:min(i+100, len(data))])
+		}
+		p := w.Profile()
+		if len(p.Windows) != (len(data)-1024)/512+1 {
+			t.Fatalf("expected %d windows, got %d", (len(data)-1024)/512+1, len(p.Windows))
+		}
+		for i, win := range p.Windows {
+			ent := NewWriter()
+			ent.Write(data[win.Offset : win.Offset+win.Size])
+			if win.Offset != uint64(i*512) || win.Size != 1024 || win.Entropy != ent.Entropy() {
+				t.Errorf("expected window %d at %d with %v, got %+v", i, i*512, ent.Entropy(), win)
+			}
+		}
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		w, _ := NewWindowWriter(1024, 1024)
+		w.Write(data[:1500])
+		p := w.Profile()
+		if len(p.Windows) != 2 {
+			t.Fatalf("expected 2 windows, got %d", len(p.Windows))
+		}
+		// last window is the last block size bytes
+		if last := p.Windows[1]; last.Offset != 476 || last.Size != 1024 {
+			t.Errorf("expected last window at 476 with size 1024, got %+v", last)
+		}
+
+		w.Reset()
+		w.Write([]byte("abc"))
+		p = w.Profile()
+		if len(p.Windows) != 1 || p.Windows[0].Size != 3 || p.Size != 3 {
+			t.Errorf("expected 1 window with size 3, got %+v", p)
+		}
+
+		w.Reset()
+		if p := w.Profile(); len(p.Windows) != 0 {
+			t.Errorf("expected no windows, got %+v", p)
+		}
+	})
+
+	t.Run("regions", func(t *testing.T) {
+		w, _ := NewWindowWriter(1024, 256)
+		w.Write(data)
+		p := w.Profile()
+
+		high := p.HighRegions(7)
+		if len(high) != 1 || high[0].Offset < 3072 || high[0].Offset > 4096 || high[0].Offset+high[0].Size > 9216 {
+			t.Errorf("expected 1 high region around the random data, got %+v", high)
+		}
+		low := p.LowRegions(2)
+		if len(low) != 2 || low[0].Offset != 0 || low[0].Entropy != 0 || low[1].Offset+low[1].Size != uint64(len(data)) {
+			t.Errorf("expected 2 low regions for the zeros and text, got %+v", low)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		w, _ := NewWindowWriter(1024, 1024)
+		w.Write(data)
+		b, err := json.Marshal(w.Profile())
+		if err != nil {
+			t.Fatalf("expected nil error for marshal, got %v", err)
+		}
+		var p Profile
+		if err := json.Unmarshal(b, &p); err != nil {
+			t.Fatalf("expected nil error for unmarshal, got %v", err)
+		}
+		if !reflect.DeepEqual(p, w.Profile()) {
+			t.Errorf("expected %+v, got %+v", w.Profile(), p)
+		}
+	})
+}
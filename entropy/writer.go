@@ -9,34 +9,55 @@ type Writer struct {
 	// Write a slice of bytes to the entropy writer
 	frequency [256]uint64
 	count     uint64
+	offset    uint64 // where the data starts if this is a segment of a bigger stream
+	stats     bool   // track the serial correlation and monte carlo points for Stats
+	serial    serial
+	monte     monte
 }
 
 func NewWriter() *Writer {
 	return &Writer{frequency: [256]uint64{}}
 }
 
+// NewStatsWriter creates a writer that also keeps what Stats needs for the serial
+// correlation and monte carlo pi, which is slower so NewWriter only counts bytes
+func NewStatsWriter() *Writer {
+	return &Writer{stats: true}
+}
+
 func (w *Writer) Write(p []byte) (n int, err error) {
 	for _, b := range p {
 		w.frequency[b]++
 	}
+	if w.stats {
+		w.serial.write(p, w.count)
+		w.monte.write(p)
+	}
 	size := len(p)
 	w.count += uint64(size)
 	return size, nil
 }
 
 func (w *Writer) Entropy() float64 {
+	return entropy(&w.frequency, w.count)
+}
+
+func entropy(frequency *[256]uint64, count uint64) float64 {
 	ent := float64(0.0)
-	size := float64(w.count)
+	size := float64(count)
 	for i := 0; i < 256; i++ {
-		if w.frequency[i] > 0 {
-			p := float64(w.frequency[i]) / size
+		if frequency[i] > 0 {
+			p := float64(frequency[i]) / size
 			ent -= p * math.Log2(p)
 		}
 	}
 	return ent
 }
 
+// Reset resets the writer, a segment writer keeps its offset and a stats writer keeps tracking stats
 func (w *Writer) Reset() {
 	w.frequency = [256]uint64{}
 	w.count = 0
+	w.serial = serial{}
+	w.monte = monte{headLen: w.monte.headLen}
 }
@@ -0,0 +1,150 @@
+package filetype
+
+import (
+	"slices"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// Category is a coarse grouping of file types so policies dont need to list every mimetype
+type Category string
+
+const (
+	Archive    Category = "archive"
+	Executable Category = "executable"
+	Image      Category = "image"
+	Document   Category = "document"
+	Text       Category = "text"
+	Audio      Category = "audio"
+	Video      Category = "video"
+	Unknown    Category = "unknown"
+)
+
+// categories are checked in order against the file type and its parents, so more
+// specific categories come first (a docx is a document before its an archive)
+var categories = []struct {
+	category Category
+	mimes    []string
+}{
+	{Executable, []string{
+		"application/vnd.microsoft.portable-executable", "application/x-elf", "application/x-mach-binary",
+		"application/x-java-applet", "application/wasm", "application/x-ms-shortcut", "application/x-chrome-extension",
+		"application/vnd.android.package-archive",
+	}},
+	{Document, []string{
+		"application/pdf", "application/postscript", "application/x-ole-storage", "application/msword",
+		"application/vnd.ms-excel", "application/vnd.ms-powerpoint", "application/vnd.ms-publisher", "application/vnd.ms-outlook",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		"application/vnd.oasis.opendocument.text", "application/vnd.oasis.opendocument.spreadsheet",
+		"application/vnd.oasis.opendocument.presentation", "application/vnd.oasis.opendocument.graphics",
+		"application/vnd.oasis.opendocument.formula", "application/vnd.oasis.opendocument.chart",
+		"application/vnd.sun.xml.calc", "application/epub+zip", "application/x-mobipocket-ebook", "application/x-ms-reader",
+		"image/vnd.djvu", "text/rtf",
+	}},
+	{Image, []string{"image/*"}},
+	{Audio, []string{"audio/*"}},
+	{Video, []string{"video/*"}},
+	{Archive, []string{
+		"application/zip", "application/gzip", "application/x-tar", "application/x-7z-compressed", "application/x-rar-compressed",
+		"application/x-xz", "application/x-bzip2", "application/zstd", "application/lzip", "application/x-xar",
+		"application/vnd.ms-cab-compressed", "application/x-installshield", "application/x-archive", "application/x-rpm",
+		"application/x-cpio", "application/x-ms-installer",
+	}},
+	{Text, []string{"text/*"}},
+}
+
+// category returns the first category the file type (or a parent) is in
+func (f Filetype) category() Category {
+	for _, c := range categories {
+		if f.Is(c.mimes...) {
+			return c.category
+		}
+	}
+	return Unknown
+}
+
+// IsCategory returns true if the file type is in any of the categories
+func (f Filetype) IsCategory(categories ...Category) bool {
+	for _, c := range categories {
+		if f.Category == c {
+			return true
+		}
+	}
+	return false
+}
+
+// parents returns the mimetypes of the parents of m closest first, without the root
+// application/octet-stream since everything is a child of it
+func parents(m *mimetype.MIME) []string {
+	var toReturn []string
+	for p := m.Parent(); p != nil && p.Parent() != nil; p = p.Parent() {
+		toReturn = append(toReturn, p.String())
+	}
+	return toReturn
+}
+
+// mimeAliases are the other mimetypes the mimetype library knows a type by, it only
+// uses them in MIME.Is and Lookup without exporting them
+var mimeAliases = map[string][]string{
+	"application/gzip": {
+		"application/x-gzip", "application/x-gunzip", "application/gzipped", "application/gzip-compressed",
+		"application/x-gzip-compressed", "gzip/document",
+	},
+	"application/lzip":                                         {"application/x-lzip"},
+	"application/msword":                                       {"application/vnd.ms-word"},
+	"application/ogg":                                          {"application/x-ogg"},
+	"application/pdf":                                          {"application/x-pdf"},
+	"application/rss+xml":                                      {"text/rss"},
+	"application/vnd.apache.parquet":                           {"application/x-parquet"},
+	"application/vnd.apple.mpegurl":                            {"audio/mpegurl"},
+	"application/vnd.ms-excel":                                 {"application/msexcel"},
+	"application/vnd.ms-powerpoint":                            {"application/mspowerpoint"},
+	"application/vnd.oasis.opendocument.chart":                 {"application/x-vnd.oasis.opendocument.chart"},
+	"application/vnd.oasis.opendocument.formula":               {"application/x-vnd.oasis.opendocument.formula"},
+	"application/vnd.oasis.opendocument.graphics":              {"application/x-vnd.oasis.opendocument.graphics"},
+	"application/vnd.oasis.opendocument.graphics-template":     {"application/x-vnd.oasis.opendocument.graphics-template"},
+	"application/vnd.oasis.opendocument.presentation":          {"application/x-vnd.oasis.opendocument.presentation"},
+	"application/vnd.oasis.opendocument.presentation-template": {"application/x-vnd.oasis.opendocument.presentation-template"},
+	"application/vnd.oasis.opendocument.spreadsheet":           {"application/x-vnd.oasis.opendocument.spreadsheet"},
+	"application/vnd.oasis.opendocument.spreadsheet-template":  {"application/x-vnd.oasis.opendocument.spreadsheet-template"},
+	"application/vnd.oasis.opendocument.text":                  {"application/x-vnd.oasis.opendocument.text"},
+	"application/vnd.oasis.opendocument.text-template":         {"application/x-vnd.oasis.opendocument.text-template"},
+	"application/vnd.sqlite3":                                  {"application/x-sqlite3"},
+	"application/x-archive":                                    {"application/x-unix-archive"},
+	"application/x-ms-installer":                               {"application/x-windows-installer", "application/x-msi"},
+	"application/x-rar-compressed":                             {"application/x-rar"},
+	"application/x-subrip":                                     {"application/x-srt", "text/x-srt"},
+	"application/zip":                                          {"application/x-zip", "application/x-zip-compressed"},
+	"audio/aiff":                                               {"audio/x-aiff"},
+	"audio/amr":                                                {"audio/amr-nb"},
+	"audio/midi":                                               {"audio/mid", "audio/sp-midi", "audio/x-mid", "audio/x-midi"},
+	"audio/mp4":                                                {"audio/x-mp4a"},
+	"audio/mpeg":                                               {"audio/x-mpeg", "audio/mp3"},
+	"audio/wav":                                                {"audio/x-wav", "audio/vnd.wave", "audio/wave"},
+	"font/ttf":                                                 {"font/sfnt", "application/x-font-ttf", "application/font-sfnt"},
+	"image/bmp":                                                {"image/x-bmp", "image/x-ms-bmp"},
+	"image/jpm":                                                {"video/jpm"},
+	"image/jxr":                                                {"image/vnd.ms-photo"},
+	"image/vnd.adobe.photoshop":                                {"image/x-psd", "application/photoshop"},
+	"image/vnd.dwg": {
+		"image/x-dwg", "application/acad", "application/x-acad", "application/autocad_dwg", "application/dwg",
+		"application/x-dwg", "application/x-autocad", "drawing/dwg",
+	},
+	"text/javascript": {"application/x-javascript", "application/javascript"},
+	"text/rtf":        {"application/rtf"},
+	"text/x-python":   {"text/x-script.python", "application/x-python"},
+	"text/x-tcl":      {"application/x-tcl"},
+	"text/xml":        {"application/xml"},
+	"video/3gpp":      {"video/3gp", "audio/3gpp"},
+	"video/3gpp2":     {"video/3g2", "audio/3gpp2"},
+	"video/webm":      {"audio/webm"},
+	"video/x-ms-asf":  {"video/asf", "video/x-ms-wmv"},
+	"video/x-msvideo": {"video/avi", "video/msvideo"},
+}
+
+// aliases returns the other mimetypes m is known by
+func aliases(m *mimetype.MIME) []string {
+	return slices.Clone(mimeAliases[m.String()])
+}
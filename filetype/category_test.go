@@ -0,0 +1,95 @@
+package filetype
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+func TestCategory(t *testing.T) {
+	gz, err := gzipCompress([]byte("Something cool"))
+	if err != nil {
+		t.Fatalf("failed to gz compress data %v", err)
+	}
+	elf := append([]byte("\x7fELF\x02\x01\x01"), make([]byte, 64)...)
+	elf[16] = 2 // executable
+
+	tests := []struct {
+		data     []byte
+		mime     string
+		category Category
+	}{
+		{gz, "application/gzip", Archive},
+		{[]byte("\x89PNG\r\n\x1a\n"), "image/png", Image},
+		{[]byte("%PDF-1.7\n"), "application/pdf", Document},
+		{[]byte("Something cool"), "text/plain; charset=utf-8", Text},
+		{[]byte("<html><body></body></html>"), "text/html; charset=utf-8", Text},
+		{elf, "application/x-executable", Executable},
+		{[]byte("ID3\x03\x00"), "audio/mpeg", Audio},
+		{[]byte{0x00, 0x01, 0x02, 0x03, 0xff}, "application/octet-stream", Unknown},
+	}
+	for _, test := range tests {
+		ft := NewFiletypeFromBytes(test.data)
+		if ft.Mimetype != test.mime {
+			t.Errorf("expected %s, got %s", test.mime, ft.Mimetype)
+		}
+		if ft.Category != test.category {
+			t.Errorf("expected %s category for %s, got %s", test.category, test.mime, ft.Category)
+		}
+		if !ft.IsCategory(Video, test.category) {
+			t.Errorf("expected %s to be in %s", test.mime, test.category)
+		}
+	}
+
+	docx := newFiletype(mimetype.Lookup("application/vnd.openxmlformats-officedocument.wordprocessingml.document"))
+	if docx.Category != Document || docx.IsCategory(Archive) {
+		t.Errorf("expected docx to be a document, got %s", docx.Category)
+	}
+	if !reflect.DeepEqual(docx.Parents, []string{"application/zip"}) {
+		t.Errorf("expected application/zip parent, got %v", docx.Parents)
+	}
+
+	zip := newFiletype(mimetype.Lookup("application/zip"))
+	if zip.Category != Archive || zip.Parents != nil {
+		t.Errorf("expected zip to be an archive without parents, got %s %v", zip.Category, zip.Parents)
+	}
+	if !reflect.DeepEqual(zip.Aliases, []string{"application/x-zip", "application/x-zip-compressed"}) {
+		t.Errorf("expected zip aliases, got %v", zip.Aliases)
+	}
+
+	csv := NewFiletypeFromBytes([]byte("a,b,c\n1,2,3\n4,5,6\n"))
+	if !reflect.DeepEqual(csv.Parents, []string{"text/plain"}) || csv.Category != Text {
+		t.Errorf("expected text/plain parent and text category, got %v %s", csv.Parents, csv.Category)
+	}
+
+	if Dir.Category != "" || Dir.IsCategory(Unknown) {
+		t.Errorf("expected no category for dir, got %s", Dir.Category)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	for mime, list := range mimeAliases {
+		for _, alias := range list {
+			if m := mimetype.Lookup(alias); m == nil || m.String() != mime {
+				t.Errorf("expected %s to be an alias of %s, got %v", alias, mime, m)
+			}
+		}
+	}
+	if a := aliases(mimetype.Lookup("image/png")); a != nil {
+		t.Errorf("expected no aliases for png, got %v", a)
+	}
+}
+
+func TestFiletypeFromJson(t *testing.T) {
+	v := map[string]any{
+		"extension": ".docx",
+		"mimetype":  "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"parents":   []any{"application/zip"},
+		"category":  "document",
+	}
+	ft := FiletypeFromJson(v)
+	if !reflect.DeepEqual(ft.Parents, []string{"application/zip"}) || ft.Aliases != nil || ft.Category != Document {
+		t.Errorf("expected parents and category from json, got %+v", ft)
+	}
+}
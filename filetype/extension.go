@@ -0,0 +1,79 @@
+package filetype
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// extensions are the other extensions files of a mimetype are saved with, the
+// mimetype library only gives one. It includes aliases (.jpeg for .jpg) and for
+// generic types the extensions of the more specific types detection falls back
+// to (a csv can be detected as text/plain, a docx as a zip)
+var extensions = map[string][]string{
+	"text/plain": {
+		".text", ".log", ".md", ".markdown", ".rst", ".csv", ".tsv", ".json", ".ndjson", ".xml", ".html", ".htm",
+		".yaml", ".yml", ".toml", ".ini", ".cfg", ".conf", ".env", ".properties", ".sql", ".css", ".sh", ".bash",
+		".bat", ".ps1", ".go", ".c", ".h", ".cpp", ".hpp", ".java", ".rb", ".rs", ".ts", ".tex", ".svg", ".js",
+		".py", ".pl", ".php", ".lua", ".srt", ".vtt", ".vcf", ".ics",
+	},
+	"text/xml":         {".xsd", ".xsl", ".xslt", ".plist", ".svg", ".rss", ".atom", ".kml", ".gpx", ".xhtml"},
+	"application/json": {".geojson", ".har", ".gltf", ".webmanifest"},
+	"application/zip": {
+		".docx", ".xlsx", ".pptx", ".epub", ".jar", ".war", ".ear", ".apk", ".aab", ".ipa", ".xpi", ".whl",
+		".nupkg", ".vsix", ".odt", ".ods", ".odp", ".odg", ".kmz", ".3mf",
+	},
+	"application/x-ole-storage":                     {".doc", ".dot", ".xls", ".xlt", ".ppt", ".pot", ".msi", ".msg", ".pub"},
+	"application/x-elf":                             {".elf", ".o", ".so", ".ko", ".axf", ".prx"},
+	"application/vnd.microsoft.portable-executable": {".dll", ".sys", ".scr", ".efi", ".ocx", ".cpl", ".drv", ".mui"},
+	"application/x-mach-binary":                     {".dylib", ".bundle"},
+	"application/gzip":                              {".tgz", ".gzip"},
+	"application/x-bzip2":                           {".tbz", ".tbz2", ".bzip2"},
+	"application/x-xz":                              {".txz"},
+	"application/zstd":                              {".tzst", ".zstd"},
+	"application/vnd.sqlite3":                       {".db", ".sqlite3"},
+	"application/postscript":                        {".eps", ".ai"},
+	"image/jpeg":                                    {".jpeg", ".jpe", ".jfif"},
+	"image/tiff":                                    {".tif"},
+	"image/x-icon":                                  {".cur"},
+	"text/html":                                     {".htm", ".xhtml"},
+	"text/javascript":                               {".mjs", ".cjs"},
+	"text/x-perl":                                   {".pm"},
+	"text/vcard":                                    {".vcard"},
+	"audio/midi":                                    {".mid"},
+	"audio/aiff":                                    {".aif", ".aifc"},
+	"audio/wav":                                     {".wave"},
+	"audio/mpeg":                                    {".mpga"},
+	"video/mpeg":                                    {".mpg", ".mpe", ".m1v", ".m2v"},
+	"video/mp4":                                     {".m4v", ".m4p"},
+	"video/quicktime":                               {".qt"},
+	"application/ogg":                               {".ogx"},
+}
+
+// MatchesFilename returns true if the extension of the file name agrees with the
+// detected file type. A name agrees if its extension is the extension (or an alias)
+// of the file type or of any of its parents (except the root application/octet-stream,
+// so an executable named invoice.dat doesnt agree), so report.txt agrees with a detected
+// text/csv and archive.zip with a docx. Names without an extension and unknown
+// content (application/octet-stream) agree with anything. The comparison is case insensitive
+func MatchesFilename(name string, f Filetype) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" || ext == "." || ext == strings.ToLower(f.Extension) {
+		return true
+	}
+
+	m := f.lookup()
+	if m == nil {
+		// not a library type so only its own extension can agree
+		return f.Extension == ""
+	}
+	if m.Parent() == nil {
+		return true
+	}
+	for ; m.Parent() != nil; m = m.Parent() {
+		if ext == m.Extension() || slices.Contains(extensions[m.String()], ext) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package filetype
+
+import "testing"
+
+func TestMatchesFilename(t *testing.T) {
+	jpg := Filetype{Extension: ".jpg", Mimetype: "image/jpeg"}
+	text := Filetype{Extension: ".txt", Mimetype: "text/plain; charset=utf-8"}
+	csv := Filetype{Extension: ".csv", Mimetype: "text/csv; charset=utf-8"}
+	docx := Filetype{Extension: ".docx", Mimetype: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"}
+	exe := Filetype{Extension: ".exe", Mimetype: "application/vnd.microsoft.portable-executable"}
+	unknown := Filetype{Extension: "", Mimetype: "application/octet-stream"}
+
+	tests := []struct {
+		name     string
+		filetype Filetype
+		expected bool
+	}{
+		{"photo.jpg", jpg, true},
+		{"photo.JPEG", jpg, true},
+		{"dir/photo.jfif", jpg, true},
+		{"photo.png", jpg, false},
+		{"photo", jpg, true},
+		{"data.csv", text, true},
+		{"data.csv", csv, true},
+		{"data.txt", csv, true},
+		{"data.jpg", csv, false},
+		{"report.docx", docx, true},
+		{"report.zip", docx, true},
+		{"report.pdf", docx, false},
+		{"setup.exe", exe, true},
+		{"library.dll", exe, true},
+		{"invoice.pdf", exe, false},
+		{"invoice.txt", exe, false},
+		{"firmware.bin", exe, false},
+		{"invoice.dat", exe, false},
+		{"invoice.dat", Filetype{Extension: "", Mimetype: "application/x-elf"}, false},
+		{"data.bin", text, false},
+		{"anything.pdf", unknown, true},
+		{"custom.foo", Filetype{Extension: ".foo", Mimetype: "application/x-not-known"}, true},
+		{"custom.bar", Filetype{Extension: ".foo", Mimetype: "application/x-not-known"}, false},
+	}
+	for _, test := range tests {
+		if actual := MatchesFilename(test.name, test.filetype); actual != test.expected {
+			t.Errorf("expected %v for %s and %s, got %v", test.expected, test.name, test.filetype.Mimetype, actual)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package filetype
 
 import (
 	"io"
+	"mime"
+	"strings"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/jonathongardner/fifo/cache"
@@ -28,9 +30,14 @@ func init() {
 }
 
 // Filetype represents a file type with its extension and MIME type.
+// Parents, Aliases and Category are only set for file types the mimetype library knows
 type Filetype struct {
-	Extension string `json:"extension"`
-	Mimetype  string `json:"mimetype"`
+	Extension string   `json:"extension"`
+	Mimetype  string   `json:"mimetype"`
+	Parents   []string `json:"parents,omitempty"` // closest first, i.e. application/zip for a docx
+	Aliases   []string `json:"aliases,omitempty"` // other mimetypes its known by, i.e. application/x-zip
+	Category  Category `json:"category,omitempty"`
+	Rule      string   `json:"rule,omitempty"` // name of the custom rule that matched, see RegisterRules
 }
 
 // Dir is a predefined Filetype for directories.
@@ -39,14 +46,95 @@ var Dir = Filetype{Extension: "dir", Mimetype: "directory/directory"}
 // Symlink is a predefined Filetype for symbolic links.
 var Symlink = Filetype{Extension: "symlink", Mimetype: "symlink/symlink"}
 
+// Is returns true if the file type is, or is a child of, any of the given mimetypes
+// i.e. a docx is also an application/zip. Wildcards like image/* match any subtype,
+// application/* only matches application/octet-stream if its the file type itself
+func (f Filetype) Is(mimes ...string) bool {
+	if f.lookup() == nil {
+		// not a library type (i.e. from a rule) so only its own mimetype and parents
+		for _, mime := range mimes {
+			for _, m := range append([]string{f.Mimetype}, f.Parents...) {
+				if matchWildcard(m, mime) || m == mime {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	first := f.lookup()
+	for m := first; m != nil; m = m.Parent() {
+		// everything is a child of the root application/octet-stream, so wildcards only
+		// match it if its the file type itself or application/* would match anything
+		wildcard := m == first || m.Parent() != nil
+		for _, mime := range mimes {
+			if (wildcard && matchWildcard(m.String(), mime)) || m.Is(mime) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsExactly is like Is but doesnt match the parents, only the mimetype of the file type,
+// its aliases and wildcards like image/* (a docx isnt an application/zip)
+func (f Filetype) IsExactly(mimes ...string) bool {
+	own := append([]string{f.Mimetype}, f.Aliases...)
+	l := f.lookup()
+	if l != nil {
+		own = append(own, l.String())
+	}
+	for _, mime := range mimes {
+		if l != nil && l.Is(mime) {
+			return true
+		}
+		for _, m := range own {
+			if matchWildcard(m, mime) || m == mime {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lookup returns the mimetype of the file type without parameters like the charset
+// (detected text is text/plain; charset=utf-8), nil if the library doesnt know it
+func (f Filetype) lookup() *mimetype.MIME {
+	m, _, err := mime.ParseMediaType(f.Mimetype)
+	if err != nil {
+		return nil
+	}
+	return mimetype.Lookup(m)
+}
+
+// matchWildcard returns true if pattern is * (or */*) or type/* and mime is that type
+func matchWildcard(mime, pattern string) bool {
+	if pattern == "*" || pattern == "*/*" {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pattern, "/*")
+	return ok && strings.HasPrefix(mime, prefix+"/")
+}
+
 // newFiletype creates a new Filetype instance from a mimetype.MIME object.
 func newFiletype(mtype *mimetype.MIME) Filetype {
-	return Filetype{Extension: mtype.Extension(), Mimetype: mtype.String()}
+	toReturn := Filetype{
+		Extension: mtype.Extension(),
+		Mimetype:  mtype.String(),
+		Parents:   parents(mtype),
+		Aliases:   aliases(mtype),
+	}
+	toReturn.Category = toReturn.category()
+	return toReturn
 }
 
 // NewFiletypeFromCached creates a new Filetype instance from a cached writer
 func NewFiletypeFromCached(wr *cache.Writer) Filetype {
-	return newFiletype(mimetype.Detect(wr.Bytes()))
+	return detect(wr.Bytes(), nil)
+}
+
+// NewFiletypeFromBytes creates a new Filetype instance from the start of a stream
+func NewFiletypeFromBytes(data []byte) Filetype {
+	return detect(data, nil)
 }
 
 // NewFiletypeFromPath creates a new Filetype instance from a reader
@@ -61,10 +149,34 @@ func NewFiletypeFromReader(reader io.Reader) (Filetype, error) {
 	if uint32(w) < maxBytesFileDetect {
 		data = data[:w]
 	}
-	return newFiletype(mimetype.Detect(data)), nil
+	return detect(data, nil), nil
 }
 
 // FiletypeFromJson creates a Filetype instance from a JSON representation.
 func FiletypeFromJson(v map[string]any) Filetype {
-	return Filetype{Extension: v["extension"].(string), Mimetype: v["mimetype"].(string)}
+	toReturn := Filetype{Extension: v["extension"].(string), Mimetype: v["mimetype"].(string)}
+	toReturn.Parents = stringsFromJson(v["parents"])
+	toReturn.Aliases = stringsFromJson(v["aliases"])
+	if c, ok := v["category"].(string); ok {
+		toReturn.Category = Category(c)
+	}
+	if r, ok := v["rule"].(string); ok {
+		toReturn.Rule = r
+	}
+	return toReturn
+}
+
+// stringsFromJson converts a decoded JSON array to strings, nil if its missing
+func stringsFromJson(v any) []string {
+	values, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	toReturn := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			toReturn = append(toReturn, s)
+		}
+	}
+	return toReturn
 }
@@ -0,0 +1,311 @@
+package filetype
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gabriel-vasile/mimetype"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidRule = fmt.Errorf("invalid rule")
+var ErrDuplicateRule = fmt.Errorf("rule already registered")
+
+// Rule is a custom signature for file types the mimetype library doesnt know (which
+// are detected as application/octet-stream). It matches if all the signatures and the
+// regex (if set) match the header, the first MaxBytesFileDetect bytes, and all the
+// trailer signatures and trailer regex match the tail (the end of the stream).
+// Rules with trailer conditions only match once the tail is known, see
+// NewFiletypeFromHeadTail, and rules with only trailer conditions give the
+// trailer file type, see NewTrailerFiletype.
+// Rules are checked in the order they are registered, Before rules are checked before
+// the built in detection and the rest only if it finds a generic type (application/octet-stream
+// or text/plain). The YAML keys are the same as the JSON ones, see LoadRulesFile
+type Rule struct {
+	Name         string      `json:"name" yaml:"name"`
+	Mimetype     string      `json:"mimetype" yaml:"mimetype"`
+	Extension    string      `json:"extension,omitempty" yaml:"extension,omitempty"`
+	Category     Category    `json:"category,omitempty" yaml:"category,omitempty"` // defaults to the category of the mimetype
+	Before       bool        `json:"before,omitempty" yaml:"before,omitempty"`
+	Signatures   []Signature `json:"signatures,omitempty" yaml:"signatures,omitempty"`
+	Regex        string      `json:"regex,omitempty" yaml:"regex,omitempty"`
+	Trailer      []Signature `json:"trailer,omitempty" yaml:"trailer,omitempty"` // offsets count back from the end
+	TrailerRegex string      `json:"trailer_regex,omitempty" yaml:"trailer_regex,omitempty"`
+}
+
+// Signature is a byte pattern at an offset in the header, if Mask is set it has
+// to be the same length as Bytes and only the bits set in it are compared.
+// In a trailer the offset is how far before the end of the stream the bytes start
+type Signature struct {
+	Offset int `json:"offset" yaml:"offset"`
+	Bytes  Hex `json:"bytes" yaml:"bytes"`
+	Mask   Hex `json:"mask,omitempty" yaml:"mask,omitempty"`
+}
+
+// Hex is bytes encoded as a hex string in JSON (and YAML), i.e. "89504e47"
+type Hex []byte
+
+func (h Hex) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(h)), nil
+}
+
+func (h *Hex) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	*h = b
+	return nil
+}
+
+// rule is a registered Rule with its regexes compiled
+type rule struct {
+	Rule
+	regex        *regexp.Regexp
+	trailerRegex *regexp.Regexp
+}
+
+var (
+	rulesMu sync.RWMutex
+	rules   []*rule
+)
+
+// RegisterRules adds custom signatures, either all the rules are added or none
+// if any is invalid or has the name of a registered rule
+func RegisterRules(toAdd ...Rule) error {
+	compiled := make([]*rule, 0, len(toAdd))
+	names := make(map[string]bool)
+	for _, r := range toAdd {
+		c, err := compileRule(r)
+		if err != nil {
+			return err
+		}
+		if names[r.Name] {
+			return fmt.Errorf("%w: %s", ErrDuplicateRule, r.Name)
+		}
+		names[r.Name] = true
+		compiled = append(compiled, c)
+	}
+
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	for _, r := range rules {
+		if names[r.Name] {
+			return fmt.Errorf("%w: %s", ErrDuplicateRule, r.Name)
+		}
+	}
+	rules = append(rules, compiled...)
+	return nil
+}
+
+// LoadRules registers the rules from JSON in the form {"rules": [...]}, see RegisterRules
+func LoadRules(r io.Reader) error {
+	var file struct {
+		Rules []Rule `json:"rules"`
+	}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRule, err)
+	}
+	return RegisterRules(file.Rules...)
+}
+
+// LoadRulesYAML registers the rules from YAML in the form rules: [...], see RegisterRules
+func LoadRulesYAML(r io.Reader) error {
+	var file struct {
+		Rules []Rule `yaml:"rules"`
+	}
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRule, err)
+	}
+	return RegisterRules(file.Rules...)
+}
+
+// LoadRulesFile registers the rules from a YAML file if it ends in .yaml or .yml
+// and from a JSON file otherwise, see LoadRules and LoadRulesYAML
+func LoadRulesFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return LoadRulesYAML(f)
+	}
+	return LoadRules(f)
+}
+
+// UnregisterRule removes a rule, it returns false if no rule has the name
+func UnregisterRule(name string) bool {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	for i, r := range rules {
+		if r.Name == name {
+			rules = append(rules[:i], rules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// RegisteredRules returns a sorted list of the names of the registered rules
+func RegisteredRules() []string {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	names := make([]string, 0, len(rules))
+	for _, r := range rules {
+		names = append(names, r.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// compileRule validates the rule and compiles its regexes
+func compileRule(r Rule) (*rule, error) {
+	if r.Name == "" || r.Mimetype == "" {
+		return nil, fmt.Errorf("%w: name and mimetype are required", ErrInvalidRule)
+	}
+	if len(r.Signatures) == 0 && r.Regex == "" && len(r.Trailer) == 0 && r.TrailerRegex == "" {
+		return nil, fmt.Errorf("%w: %s needs a signature or regex", ErrInvalidRule, r.Name)
+	}
+	for _, s := range r.Signatures {
+		if s.Offset < 0 || len(s.Bytes) == 0 {
+			return nil, fmt.Errorf("%w: %s signatures need bytes at a positive offset", ErrInvalidRule, r.Name)
+		}
+		if s.Mask != nil && len(s.Mask) != len(s.Bytes) {
+			return nil, fmt.Errorf("%w: %s signature mask is not the length of the bytes", ErrInvalidRule, r.Name)
+		}
+	}
+	for _, s := range r.Trailer {
+		if len(s.Bytes) == 0 || s.Offset < len(s.Bytes) {
+			return nil, fmt.Errorf("%w: %s trailer signatures need bytes that end before the end", ErrInvalidRule, r.Name)
+		}
+		if s.Mask != nil && len(s.Mask) != len(s.Bytes) {
+			return nil, fmt.Errorf("%w: %s signature mask is not the length of the bytes", ErrInvalidRule, r.Name)
+		}
+	}
+	toReturn := &rule{Rule: r}
+	var err error
+	if r.Regex != "" {
+		if toReturn.regex, err = regexp.Compile(r.Regex); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidRule, r.Name, err)
+		}
+	}
+	if r.TrailerRegex != "" {
+		if toReturn.trailerRegex, err = regexp.Compile(r.TrailerRegex); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidRule, r.Name, err)
+		}
+	}
+	return toReturn, nil
+}
+
+// hasHead returns true if the rule has conditions on the header
+func (r *rule) hasHead() bool {
+	return len(r.Signatures) > 0 || r.regex != nil
+}
+
+// hasTrailer returns true if the rule has conditions on the tail
+func (r *rule) hasTrailer() bool {
+	return len(r.Trailer) > 0 || r.trailerRegex != nil
+}
+
+// match returns true if all the conditions match, tail is nil if its not known
+func (r *rule) match(header, tail []byte) bool {
+	if r.hasTrailer() && tail == nil {
+		return false
+	}
+	for _, s := range r.Signatures {
+		if !s.match(header, s.Offset) {
+			return false
+		}
+	}
+	for _, s := range r.Trailer {
+		if !s.match(tail, len(tail)-s.Offset) {
+			return false
+		}
+	}
+	return (r.regex == nil || r.regex.Match(header)) && (r.trailerRegex == nil || r.trailerRegex.Match(tail))
+}
+
+// match returns true if the bytes are in data at offset
+func (s Signature) match(data []byte, offset int) bool {
+	if offset < 0 || offset+len(s.Bytes) > len(data) {
+		return false
+	}
+	data = data[offset : offset+len(s.Bytes)]
+	if s.Mask == nil {
+		return bytes.Equal(data, s.Bytes)
+	}
+	for i, m := range s.Mask {
+		if data[i]&m != s.Bytes[i]&m {
+			return false
+		}
+	}
+	return true
+}
+
+// filetype returns the Filetype of a match
+func (r *rule) filetype() Filetype {
+	toReturn := Filetype{Extension: r.Extension, Mimetype: r.Mimetype, Rule: r.Name, Category: r.Category}
+	if m := toReturn.lookup(); m != nil {
+		// a library type, i.e. a rule for a variant it doesnt detect
+		toReturn.Parents = parents(m)
+		toReturn.Aliases = aliases(m)
+	}
+	if toReturn.Category == "" {
+		toReturn.Category = toReturn.category()
+	}
+	return toReturn
+}
+
+// matchRules returns the Filetype of the first rule with header conditions that matches
+func matchRules(header, tail []byte, before bool) (Filetype, bool) {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	for _, r := range rules {
+		if r.Before == before && r.hasHead() && r.match(header, tail) {
+			return r.filetype(), true
+		}
+	}
+	return Filetype{}, false
+}
+
+// matchTrailerRules returns the Filetype of the first rule with only trailer conditions that matches
+func matchTrailerRules(tail []byte) (Filetype, bool) {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	for _, r := range rules {
+		if !r.hasHead() && r.match(nil, tail) {
+			return r.filetype(), true
+		}
+	}
+	return Filetype{}, false
+}
+
+// detect returns the Filetype of the header using the rules and the mimetype library
+// tail is the end of the stream for rules with trailer conditions, nil if its not known
+func detect(header, tail []byte) Filetype {
+	if ft, ok := matchRules(header, tail, true); ok {
+		return ft
+	}
+	m := mimetype.Detect(header)
+	if m.Is("application/octet-stream") || m.Is("text/plain") {
+		if ft, ok := matchRules(header, tail, false); ok {
+			return ft
+		}
+	}
+	return newFiletype(m)
+}
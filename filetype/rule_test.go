@@ -0,0 +1,172 @@
+package filetype
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jonathongardner/fifo/cache"
+)
+
+func TestRules(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+	internal := append([]byte{0xca, 0xfe, 0x00, 0x2a, 0x01}, []byte("internal data")...)
+
+	t.Run("signature", func(t *testing.T) {
+		err := RegisterRules(Rule{
+			Name:      "internal",
+			Mimetype:  "application/x-internal",
+			Extension: ".int",
+			Signatures: []Signature{
+				{Offset: 0, Bytes: Hex{0xca, 0xfe}},
+				// any version in the low nibble
+				{Offset: 3, Bytes: Hex{0x20}, Mask: Hex{0xf0}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		defer UnregisterRule("internal")
+
+		w := cache.NewWriter(int64(MaxBytesFileDetect()))
+		w.Write(internal)
+		ft := NewFiletypeFromCached(w)
+		expected := Filetype{Extension: ".int", Mimetype: "application/x-internal", Category: Unknown, Rule: "internal"}
+		if !reflect.DeepEqual(ft, expected) {
+			t.Errorf("expected %+v, got %+v", expected, ft)
+		}
+		if !ft.Is("application/x-internal") || !ft.Is("application/*") || ft.Is("application/zip") {
+			t.Errorf("expected custom file type to match its own mimetype")
+		}
+
+		if ft := NewFiletypeFromBytes(internal[:3]); ft.Rule != "" || ft.Mimetype != "application/octet-stream" {
+			t.Errorf("expected no rule for a short header, got %+v", ft)
+		}
+		internal[3] = 0x42
+		if ft := NewFiletypeFromBytes(internal); ft.Rule != "" {
+			t.Errorf("expected no rule when the mask doesnt match, got %+v", ft)
+		}
+		internal[3] = 0x2a
+	})
+
+	t.Run("before and after", func(t *testing.T) {
+		pngRule := Rule{Name: "png-variant", Mimetype: "image/x-png-variant", Signatures: []Signature{{Bytes: Hex(png[:8])}}}
+		if err := RegisterRules(pngRule); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if ft := NewFiletypeFromBytes(png); ft.Mimetype != "image/png" || ft.Rule != "" {
+			t.Errorf("expected built in detection first, got %+v", ft)
+		}
+		UnregisterRule("png-variant")
+
+		pngRule.Before = true
+		if err := RegisterRules(pngRule); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		defer UnregisterRule("png-variant")
+		if ft := NewFiletypeFromBytes(png); ft.Mimetype != "image/x-png-variant" || ft.Rule != "png-variant" || ft.Category != Image {
+			t.Errorf("expected rule before built in detection, got %+v", ft)
+		}
+	})
+
+	t.Run("regex", func(t *testing.T) {
+		err := LoadRules(strings.NewReader(`{"rules": [
+			{"name": "config", "mimetype": "text/x-config", "extension": ".icfg", "regex": "^#!icfg v[0-9]+\n"},
+			{"name": "hex", "mimetype": "application/x-hex", "signatures": [{"offset": 1, "bytes": "fe00"}]}
+		]}`))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		defer UnregisterRule("config")
+		defer UnregisterRule("hex")
+		if !reflect.DeepEqual(RegisteredRules(), []string{"config", "hex"}) {
+			t.Errorf("expected config and hex rules, got %v", RegisteredRules())
+		}
+
+		ft := NewFiletypeFromBytes([]byte("#!icfg v2\nkey = value\n"))
+		if ft.Mimetype != "text/x-config" || ft.Rule != "config" || ft.Category != Text || !MatchesFilename("a.icfg", ft) {
+			t.Errorf("expected config rule, got %+v", ft)
+		}
+		if ft := NewFiletypeFromBytes([]byte("#!icfg vx\n")); ft.Rule != "" {
+			t.Errorf("expected no rule, got %+v", ft)
+		}
+		if ft := NewFiletypeFromBytes(internal); ft.Rule != "hex" {
+			t.Errorf("expected hex rule, got %+v", ft)
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rules.yml")
+		content := `rules:
+  - name: internal-yaml
+    mimetype: application/x-internal
+    extension: .int
+    signatures:
+      - offset: 0
+        bytes: cafe002a
+      - offset: 3
+        bytes: 2a00
+        mask: ff00
+`
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write rules %v", err)
+		}
+		if err := LoadRulesFile(path); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		defer UnregisterRule("internal-yaml")
+		if ft := NewFiletypeFromBytes(internal); ft.Mimetype != "application/x-internal" || ft.Rule != "internal-yaml" {
+			t.Errorf("expected yaml rule, got %+v", ft)
+		}
+
+		for _, content := range []string{"rules:\n  - name: c\n    unknown: 1\n", "rules:\n  - name: c\n    mimetype: x/c\n    signatures:\n      - bytes: zz\n"} {
+			if err := LoadRulesYAML(strings.NewReader(content)); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected invalid rule error for %s, got %v", content, err)
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		sig := []Signature{{Bytes: Hex{0x01}}}
+		invalid := []Rule{
+			{Mimetype: "application/x-a", Signatures: sig},
+			{Name: "a", Mimetype: "application/x-a"},
+			{Name: "a", Mimetype: "application/x-a", Regex: "("},
+			{Name: "a", Mimetype: "application/x-a", Signatures: []Signature{{Offset: -1, Bytes: Hex{0x01}}}},
+			{Name: "a", Mimetype: "application/x-a", Signatures: []Signature{{Bytes: Hex{0x01}, Mask: Hex{0x01, 0x02}}}},
+		}
+		for _, r := range invalid {
+			if err := RegisterRules(r); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected invalid rule error for %+v, got %v", r, err)
+			}
+		}
+
+		a := Rule{Name: "a", Mimetype: "application/x-a", Signatures: sig}
+		if err := RegisterRules(a, a); !errors.Is(err, ErrDuplicateRule) {
+			t.Errorf("expected duplicate rule error, got %v", err)
+		}
+		if err := RegisterRules(a); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		defer UnregisterRule("a")
+		b := Rule{Name: "b", Mimetype: "application/x-b", Signatures: sig}
+		if err := RegisterRules(b, a); !errors.Is(err, ErrDuplicateRule) {
+			t.Errorf("expected duplicate rule error, got %v", err)
+		}
+		if !reflect.DeepEqual(RegisteredRules(), []string{"a"}) {
+			t.Errorf("expected only a to be registered, got %v", RegisteredRules())
+		}
+
+		for _, content := range []string{`{"rules": [{"name": "c", "unknown": 1}]}`, `{"rules": [{"name": "c", "mimetype": "x/c", "signatures": [{"bytes": "zz"}]}]}`} {
+			if err := LoadRules(strings.NewReader(content)); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected invalid rule error for %s, got %v", content, err)
+			}
+		}
+		if UnregisterRule("c") {
+			t.Errorf("expected c to not be registered")
+		}
+	})
+}
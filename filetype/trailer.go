@@ -0,0 +1,58 @@
+package filetype
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// trailers are the built in formats recognized from the end of a stream
+var trailers = []struct {
+	mime  string
+	match func(tail []byte) bool
+}{
+	{"application/zip", zipTrailer},
+	{"application/pdf", pdfTrailer},
+}
+
+// zipTrailer returns true if the tail ends with a zip end of central directory
+// record, which is how self extracting archives and appended zips are found
+func zipTrailer(tail []byte) bool {
+	i := bytes.LastIndex(tail, []byte("PK\x05\x06"))
+	if i < 0 || i+22 > len(tail) {
+		return false
+	}
+	// the record is followed by its comment and nothing else
+	return int(binary.LittleEndian.Uint16(tail[i+20:])) == len(tail)-i-22
+}
+
+// pdfTrailer returns true if the tail ends with the pdf end of file marker
+func pdfTrailer(tail []byte) bool {
+	return bytes.HasSuffix(bytes.TrimRight(tail, "\r\n\t \x00"), []byte("%%EOF"))
+}
+
+// NewFiletypeFromHeadTail creates a new Filetype instance from the start and end of a
+// stream, which is the same as NewFiletypeFromBytes except rules with trailer conditions
+// can match (i.e. an executable with a zip trailer as a self extracting archive)
+func NewFiletypeFromHeadTail(head, tail []byte) Filetype {
+	if tail == nil {
+		tail = []byte{}
+	}
+	return detect(head, tail)
+}
+
+// NewTrailerFiletype returns the file type recognized from the end of a stream using
+// the rules with only trailer conditions and the built in trailers (zip and pdf),
+// false if nothing is recognized
+func NewTrailerFiletype(tail []byte) (Filetype, bool) {
+	if ft, ok := matchTrailerRules(tail); ok {
+		return ft, true
+	}
+	for _, t := range trailers {
+		if t.match(tail) {
+			return newFiletype(mimetype.Lookup(t.mime)), true
+		}
+	}
+	return Filetype{}, false
+}
@@ -0,0 +1,87 @@
+package filetype
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func zipCompress(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("file.txt")
+	if err != nil {
+		t.Fatalf("failed to create zip file %v", err)
+	}
+	f.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close zip %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestTrailer(t *testing.T) {
+	exe := append([]byte("MZ"), make([]byte, 1000)...)
+	sfx := append(append([]byte{}, exe...), zipCompress(t, []byte("Something cool"))...)
+
+	t.Run("built in", func(t *testing.T) {
+		if ft, ok := NewTrailerFiletype(sfx); !ok || ft.Mimetype != "application/zip" {
+			t.Errorf("expected zip trailer, got %+v %v", ft, ok)
+		}
+		if ft, ok := NewTrailerFiletype([]byte("1 0 obj\n<<>>\nendobj\n%%EOF\r\n")); !ok || ft.Mimetype != "application/pdf" {
+			t.Errorf("expected pdf trailer, got %+v %v", ft, ok)
+		}
+		// appended data after the zip
+		if ft, ok := NewTrailerFiletype(append(sfx, "more"...)); ok {
+			t.Errorf("expected no trailer, got %+v", ft)
+		}
+		if ft, ok := NewTrailerFiletype(exe); ok {
+			t.Errorf("expected no trailer, got %+v", ft)
+		}
+		if ft, ok := NewTrailerFiletype(nil); ok {
+			t.Errorf("expected no trailer, got %+v", ft)
+		}
+	})
+
+	t.Run("rules", func(t *testing.T) {
+		err := RegisterRules(
+			Rule{
+				Name:       "sfx",
+				Mimetype:   "application/x-sfx",
+				Before:     true,
+				Category:   Archive,
+				Signatures: []Signature{{Bytes: Hex("MZ")}},
+				Trailer:    []Signature{{Offset: 22, Bytes: Hex("PK\x05\x06")}},
+			},
+			Rule{Name: "footer", Mimetype: "application/x-footer", TrailerRegex: "FOOTER[0-9]{2}$"},
+		)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		defer UnregisterRule("sfx")
+		defer UnregisterRule("footer")
+
+		if ft := NewFiletypeFromHeadTail(sfx, sfx[len(sfx)-100:]); ft.Mimetype != "application/x-sfx" || ft.Rule != "sfx" || ft.Category != Archive {
+			t.Errorf("expected sfx rule with the tail, got %+v", ft)
+		}
+		if ft := NewFiletypeFromBytes(sfx); ft.Rule != "" {
+			t.Errorf("expected no rule without the tail, got %+v", ft)
+		}
+		if ft := NewFiletypeFromHeadTail(exe, exe); ft.Rule != "" {
+			t.Errorf("expected no rule without a zip trailer, got %+v", ft)
+		}
+
+		footer := append(append([]byte{}, exe...), "FOOTER42"...)
+		if ft, ok := NewTrailerFiletype(footer); !ok || ft.Rule != "footer" {
+			t.Errorf("expected footer trailer, got %+v %v", ft, ok)
+		}
+		if ft := NewFiletypeFromHeadTail(footer, footer); ft.Rule != "" {
+			t.Errorf("expected trailer only rules to not match the head, got %+v", ft)
+		}
+
+		invalid := Rule{Name: "a", Mimetype: "application/x-a", Trailer: []Signature{{Offset: 1, Bytes: Hex("PK")}}}
+		if err := RegisterRules(invalid); err == nil {
+			t.Errorf("expected error for a trailer past the end")
+		}
+	})
+}
@@ -120,3 +120,80 @@ func TestFiletypeWriterCustom(t *testing.T) {
 		asssertFiletype(t, w, "application/octet-stream", "")
 	})
 }
+
+func TestFiletypeIs(t *testing.T) {
+	docx := Filetype{Extension: ".docx", Mimetype: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"}
+	if !docx.Is("application/zip") {
+		t.Errorf("expected docx to be a zip")
+	}
+	if !docx.Is("application/x-tar", "application/zip") {
+		t.Errorf("expected docx to be a tar or zip")
+	}
+	if docx.Is("application/x-tar") {
+		t.Errorf("expected docx to not be a tar")
+	}
+
+	gz := Filetype{Extension: ".gz", Mimetype: "application/x-gzip"}
+	if !gz.Is("application/gzip") {
+		t.Errorf("expected alias to be a gzip")
+	}
+
+	text := Filetype{Extension: ".txt", Mimetype: "text/plain; charset=utf-8"}
+	if !text.Is("text/plain") {
+		t.Errorf("expected text with a charset to be text/plain")
+	}
+
+	if Dir.Is("application/zip") {
+		t.Errorf("expected dir to not be a zip")
+	}
+
+	png := Filetype{Extension: ".png", Mimetype: "image/png"}
+	if !png.Is("image/*") || !png.Is("*") || !docx.Is("application/*") {
+		t.Errorf("expected wildcards to match")
+	}
+	if png.Is("text/*") || png.Is("image/p*") {
+		t.Errorf("expected wildcards to not match")
+	}
+	// everything is a child of application/octet-stream
+	if png.Is("application/*") {
+		t.Errorf("expected application/* to not match through the root")
+	}
+	if bin := (Filetype{Extension: ".bin", Mimetype: "application/octet-stream"}); !bin.Is("application/*") {
+		t.Errorf("expected application/* to match application/octet-stream")
+	}
+}
+
+func TestFiletypeIsExactly(t *testing.T) {
+	docx := Filetype{Extension: ".docx", Mimetype: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"}
+	if docx.IsExactly("application/zip") {
+		t.Errorf("expected docx to not exactly be a zip")
+	}
+	if !docx.IsExactly("application/x-tar", docx.Mimetype) {
+		t.Errorf("expected docx to exactly be a docx")
+	}
+
+	gz := Filetype{Extension: ".gz", Mimetype: "application/x-gzip"}
+	if !gz.IsExactly("application/gzip") {
+		t.Errorf("expected alias to exactly be a gzip")
+	}
+	if zip := (Filetype{Extension: ".zip", Mimetype: "application/zip", Aliases: []string{"application/x-zip"}}); !zip.IsExactly("application/x-zip") {
+		t.Errorf("expected zip to exactly be its alias")
+	}
+
+	text := Filetype{Extension: ".txt", Mimetype: "text/plain; charset=utf-8"}
+	if !text.IsExactly("text/plain") || !text.IsExactly("text/*") {
+		t.Errorf("expected text with a charset to exactly be text/plain")
+	}
+	html := Filetype{Extension: ".html", Mimetype: "text/html; charset=utf-8"}
+	if html.IsExactly("text/plain") {
+		t.Errorf("expected html to not exactly be text/plain")
+	}
+
+	png := Filetype{Extension: ".png", Mimetype: "image/png"}
+	if !png.IsExactly("image/*") || !png.IsExactly("*") || png.IsExactly("application/*", "application/octet-stream") {
+		t.Errorf("expected only wildcards for the png to match")
+	}
+	if rule := (Filetype{Extension: ".int", Mimetype: "application/x-internal", Parents: []string{"application/zip"}}); !rule.IsExactly("application/x-internal") || rule.IsExactly("application/zip") {
+		t.Errorf("expected a rule type to only exactly be its own mimetype")
+	}
+}
@@ -0,0 +1,46 @@
+package flate
+
+import (
+	"compress/flate"
+	"io"
+
+	"github.com/jonathongardner/fifo"
+)
+
+var _ fifo.ReadCloseReseter = (*Reader)(nil)
+
+// Reader is a raw deflate reader that can be reset back to the start of the stream
+type Reader struct {
+	fr io.ReadCloser
+	r  io.ReadSeeker
+}
+
+// NewReader Creates a new flate reader
+// r must be a ReadSeeker, needed to seek to reset the reader
+func NewReader(r io.ReadSeeker) *Reader {
+	return &Reader{fr: flate.NewReader(r), r: r}
+}
+
+// Read reads data from the flate reader
+func (r *Reader) Read(p []byte) (int, error) {
+	return r.fr.Read(p)
+}
+
+// Close closes the flate reader and the underlying reader if it implements io.Closer
+func (r *Reader) Close() error {
+	if err := r.fr.Close(); err != nil {
+		return err
+	}
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Reset resets the flate reader to the beginning of the stream
+func (r *Reader) Reset() error {
+	if _, err := r.r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return r.fr.(flate.Resetter).Reset(r.r, nil)
+}
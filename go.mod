@@ -2,6 +2,9 @@ module github.com/jonathongardner/fifo
 
 go 1.23.5
 
-require github.com/gabriel-vasile/mimetype v1.4.9
+require (
+	github.com/gabriel-vasile/mimetype v1.4.9
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require golang.org/x/net v0.39.0 // indirect
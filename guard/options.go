@@ -0,0 +1,69 @@
+package guard
+
+import (
+	"io"
+
+	"github.com/jonathongardner/fifo/identifiers"
+)
+
+// Options is a struct that contains the limits for a guard Writer
+type Options struct {
+	Identifiers identifiers.Options
+	Allow       []string // mimetypes (wildcards like image/* work) that are allowed, not their children, empty allows everything
+	Deny        []string // mimetypes (wildcards like image/* work) and their children that are denied, checked after Allow
+	MaxSize     int64    // max number of bytes, 0 for no limit
+	MaxEntropy  float64  // max entropy checked once closed, 0 for no limit
+}
+
+// NewDefultOptions creates a new Options struct with default values
+// default identifiers and no limits
+func NewDefultOptions() Options {
+	return NewOptions(identifiers.NewDefultOptions(), nil, nil, 0, 0)
+}
+
+// NewOptions creates a new Options struct with the given options
+func NewOptions(o identifiers.Options, allow, deny []string, maxSize int64, maxEntropy float64) Options {
+	return Options{
+		Identifiers: o,
+		Allow:       allow,
+		Deny:        deny,
+		MaxSize:     maxSize,
+		MaxEntropy:  maxEntropy,
+	}
+}
+
+// UpdateIdentifiers updates the identifiers options of the Options struct
+// entropy is turned on if there is a max entropy
+func (o Options) UpdateIdentifiers(i identifiers.Options) Options {
+	o.Identifiers = i
+	return o
+}
+
+// UpdateAllow updates the allowed mimetypes of the Options struct
+func (o Options) UpdateAllow(mimes ...string) Options {
+	o.Allow = mimes
+	return o
+}
+
+// UpdateDeny updates the denied mimetypes of the Options struct
+func (o Options) UpdateDeny(mimes ...string) Options {
+	o.Deny = mimes
+	return o
+}
+
+// UpdateMaxSize updates the max size of the Options struct
+func (o Options) UpdateMaxSize(maxSize int64) Options {
+	o.MaxSize = maxSize
+	return o
+}
+
+// UpdateMaxEntropy updates the max entropy of the Options struct
+func (o Options) UpdateMaxEntropy(maxEntropy float64) Options {
+	o.MaxEntropy = maxEntropy
+	return o
+}
+
+// NewWriter creates a new guard Writer that writes to the given io.Writers
+func (o Options) NewWriter(w ...io.Writer) *Writer {
+	return newWriterWithOptions(o, w...)
+}
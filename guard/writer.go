@@ -0,0 +1,225 @@
+package guard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jonathongardner/fifo/cache"
+	"github.com/jonathongardner/fifo/filetype"
+	"github.com/jonathongardner/fifo/identifiers"
+)
+
+// ErrRejected is wrapped by every error from the guard so they can be checked with errors.Is
+var ErrRejected = errors.New("rejected")
+
+// FiletypeError is returned once the file type is known and its not allowed
+type FiletypeError struct {
+	Filetype filetype.Filetype
+}
+
+func (e *FiletypeError) Error() string {
+	return fmt.Sprintf("file type %s is not allowed", e.Filetype.Mimetype)
+}
+
+func (e *FiletypeError) Unwrap() error {
+	return ErrRejected
+}
+
+// SizeError is returned by the write that goes over the max size, none of it is written
+type SizeError struct {
+	MaxSize int64
+}
+
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("size is more than %d bytes", e.MaxSize)
+}
+
+func (e *SizeError) Unwrap() error {
+	return ErrRejected
+}
+
+// EntropyError is returned once closed if the entropy is more than the max
+type EntropyError struct {
+	Entropy    float64
+	MaxEntropy float64
+}
+
+func (e *EntropyError) Error() string {
+	return fmt.Sprintf("entropy %.4f is more than %.4f", e.Entropy, e.MaxEntropy)
+}
+
+func (e *EntropyError) Unwrap() error {
+	return ErrRejected
+}
+
+// Writer is an identifiers.Writer that stops as soon as the data breaks one of the limits,
+// the file type is checked once its known (see identifiers.Options.OnFiletype). If there
+// is an Allow or Deny the downstream writers dont get any data until the file type is allowed,
+// the first filetype.MaxBytesFileDetect bytes (or the whole stream if its shorter, until
+// Close) are held back and dropped if its not
+type Writer struct {
+	*identifiers.Writer
+	limit      *limitWriter
+	hold       *holdWriter
+	maxEntropy float64
+}
+
+// NewWriter creates a new guard Writer that allows and denies the given mimetypes
+// with default identifiers
+func NewWriter(allow, deny []string, w ...io.Writer) *Writer {
+	return NewDefultOptions().UpdateAllow(allow...).UpdateDeny(deny...).NewWriter(w...)
+}
+
+func newWriterWithOptions(o Options, w ...io.Writer) *Writer {
+	toReturn := &Writer{maxEntropy: o.MaxEntropy}
+	// the limit is written first so nothing else gets the data if its over
+	toReturn.limit = &limitWriter{max: o.MaxSize}
+	toReturn.hold = &holdWriter{check: len(o.Allow) > 0 || len(o.Deny) > 0}
+
+	i := o.Identifiers
+	if o.MaxEntropy > 0 {
+		i = i.UpdateEntropy(true)
+	}
+	if toReturn.hold.check {
+		onFiletype := i.OnFiletype
+		i = i.UpdateOnFiletype(func(ft filetype.Filetype) error {
+			if onFiletype != nil {
+				if err := onFiletype(ft); err != nil {
+					return err
+				}
+			}
+			if err := o.check(ft); err != nil {
+				return err
+			}
+			return toReturn.hold.release()
+		})
+	}
+
+	toReturn.Writer = i.NewWriter(toReturn.writers(w)...)
+	toReturn.limit.cache = toReturn.Writer.Cache()
+	return toReturn
+}
+
+// writers returns the writers for the identifiers writer, the downstream writers
+// are behind the hold
+func (w *Writer) writers(writers []io.Writer) []io.Writer {
+	w.hold.reset(writers)
+	return []io.Writer{w.limit, w.hold}
+}
+
+// check returns a FiletypeError if the file type isnt allowed, allow has to match the
+// file type exactly (text/plain doesnt allow text/html) but deny matches its parents too
+func (o Options) check(ft filetype.Filetype) error {
+	if len(o.Allow) > 0 && !ft.IsExactly(o.Allow...) {
+		return &FiletypeError{Filetype: ft}
+	}
+	if len(o.Deny) > 0 && ft.Is(o.Deny...) {
+		return &FiletypeError{Filetype: ft}
+	}
+	return nil
+}
+
+// Reset resets the writer so it can be used for a new stream
+func (w *Writer) Reset(writers ...io.Writer) {
+	w.limit.err = nil
+	w.Writer.Reset(w.writers(writers)...)
+}
+
+// Write writes p, while the downstream writers are held back its split at the end of
+// the file type detection window so no more than the window is held
+func (w *Writer) Write(p []byte) (int, error) {
+	window := int64(filetype.MaxBytesFileDetect()) - w.Cache().Size()
+	if !w.hold.held || window <= 0 || int64(len(p)) <= window {
+		return w.Writer.Write(p)
+	}
+	n, err := w.Writer.Write(p[:window])
+	if err != nil {
+		return n, err
+	}
+	m, err := w.Writer.Write(p[window:])
+	return n + m, err
+}
+
+// Close closes the writer and returns an error if any limit was broken
+func (w *Writer) Close() error {
+	if err := w.Writer.Close(); err != nil {
+		return err
+	}
+	_, err := w.Identifiers()
+	return err
+}
+
+// Identifiers returns the identifiers of the data or an error if any limit was broken
+func (w *Writer) Identifiers() (identifiers.Identifiers, error) {
+	if w.limit.err != nil {
+		return identifiers.Identifiers{}, w.limit.err
+	}
+	i, err := w.Writer.Identifiers()
+	if err != nil {
+		return identifiers.Identifiers{}, err
+	}
+	if w.maxEntropy > 0 && i.Entropy > w.maxEntropy {
+		return identifiers.Identifiers{}, &EntropyError{Entropy: i.Entropy, MaxEntropy: w.maxEntropy}
+	}
+	return i, nil
+}
+
+// Identify copies r into the writer, stopping as soon as a limit is broken, see identifiers.Writer.Identify
+func (w *Writer) Identify(ctx context.Context, r io.Reader) (identifiers.Identifiers, error) {
+	if _, err := w.Writer.Identify(ctx, r); err != nil {
+		return identifiers.Identifiers{}, err
+	}
+	return w.Identifiers()
+}
+
+// limitWriter returns an error if the data would go over the max size, the size
+// comes from the cache so it carries over if the writer is checkpointed
+type limitWriter struct {
+	max   int64
+	cache *cache.Writer
+	err   error
+}
+
+func (l *limitWriter) Write(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if l.max > 0 && l.cache.Size()+int64(len(p)) > l.max {
+		l.err = &SizeError{MaxSize: l.max}
+		return 0, l.err
+	}
+	return len(p), nil
+}
+
+// holdWriter keeps the data from the downstream writers until the file type is allowed
+type holdWriter struct {
+	w     io.Writer
+	check bool // false if there is no allow or deny so nothing is held
+	held  bool
+	buf   bytes.Buffer
+}
+
+func (h *holdWriter) Write(p []byte) (int, error) {
+	if !h.held {
+		return h.w.Write(p)
+	}
+	return h.buf.Write(p)
+}
+
+// release writes the held data to the downstream writers and stops holding
+func (h *holdWriter) release() error {
+	h.held = false
+	_, err := h.w.Write(h.buf.Bytes())
+	h.buf = bytes.Buffer{}
+	return err
+}
+
+// reset sets the downstream writers and starts holding again if the file type is checked
+func (h *holdWriter) reset(writers []io.Writer) {
+	h.w = io.MultiWriter(writers...)
+	h.held = h.check
+	h.buf = bytes.Buffer{}
+}
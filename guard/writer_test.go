@@ -0,0 +1,204 @@
+package guard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/jonathongardner/fifo/filetype"
+	"github.com/jonathongardner/fifo/identifiers"
+)
+
+func TestWriter(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 2*filetype.MaxBytesFileDetect())...)
+	text := bytes.Repeat([]byte("Something cool\n"), 1000)
+	html := []byte("<!DOCTYPE html><html><body>Something cool</body></html>")
+	random := make([]byte, 10000)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	t.Run("allow", func(t *testing.T) {
+		w := NewWriter([]string{"image/*"}, nil)
+		if _, err := w.Write(png); err != nil {
+			t.Errorf("expected nil error for png, got %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("expected nil error for close, got %v", err)
+		}
+		if i, err := w.Identifiers(); err != nil || i.Filetype.Mimetype != "image/png" {
+			t.Errorf("expected image/png, got %v (%v)", i.Filetype, err)
+		}
+
+		w.Reset()
+		_, err := w.Write(text)
+		var ftErr *FiletypeError
+		if !errors.As(err, &ftErr) || !errors.Is(err, ErrRejected) {
+			t.Fatalf("expected filetype error for text, got %v", err)
+		}
+		if ftErr.Filetype.Mimetype != "text/plain; charset=utf-8" {
+			t.Errorf("expected text/plain, got %v", ftErr.Filetype)
+		}
+
+		// detected text has a charset parameter
+		w = NewWriter([]string{"text/plain"}, nil)
+		if _, err := w.Identify(context.Background(), bytes.NewReader(text)); err != nil {
+			t.Errorf("expected nil error for text, got %v", err)
+		}
+
+		// allow doesnt take children, html is a child of text/plain
+		w.Reset()
+		if _, err := w.Identify(context.Background(), bytes.NewReader(html)); !errors.Is(err, ErrRejected) {
+			t.Errorf("expected rejected error for html, got %v", err)
+		}
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		w := NewWriter(nil, []string{"image/png"})
+		// rejected as soon as the detection window is full, not at the end
+		_, err := w.Write(png[:filetype.MaxBytesFileDetect()])
+		if !errors.Is(err, ErrRejected) {
+			t.Errorf("expected rejected error, got %v", err)
+		}
+		if _, err := w.Write(png); !errors.Is(err, ErrRejected) {
+			t.Errorf("expected rejected error for next write, got %v", err)
+		}
+		if err := w.Close(); !errors.Is(err, ErrRejected) {
+			t.Errorf("expected rejected error from close, got %v", err)
+		}
+
+		// short streams are checked on close
+		w.Reset()
+		w.Write(png[:100])
+		if err := w.Close(); !errors.Is(err, ErrRejected) {
+			t.Errorf("expected rejected error from close, got %v", err)
+		}
+
+		w.Reset()
+		if _, err := w.Identify(context.Background(), bytes.NewReader(text)); err != nil {
+			t.Errorf("expected nil error for text, got %v", err)
+		}
+
+		// deny takes children
+		w = NewWriter(nil, []string{"text/plain"})
+		if _, err := w.Identify(context.Background(), bytes.NewReader(html)); !errors.Is(err, ErrRejected) {
+			t.Errorf("expected rejected error for html, got %v", err)
+		}
+
+		// wildcards dont match everything through application/octet-stream
+		w = NewWriter(nil, []string{"application/*"})
+		if _, err := w.Identify(context.Background(), bytes.NewReader(png)); err != nil {
+			t.Errorf("expected nil error for png, got %v", err)
+		}
+		w.Reset()
+		if _, err := w.Identify(context.Background(), bytes.NewReader(random)); !errors.Is(err, ErrRejected) {
+			t.Errorf("expected rejected error for random data, got %v", err)
+		}
+	})
+
+	t.Run("downstream", func(t *testing.T) {
+		window := int(filetype.MaxBytesFileDetect())
+		var buf bytes.Buffer
+		w := NewWriter(nil, []string{"image/png"}, &buf)
+		// nothing is written downstream until the file type is allowed
+		if _, err := w.Write(text[:window-1]); err != nil || buf.Len() != 0 {
+			t.Errorf("expected nothing written downstream before the file type, got %d (%v)", buf.Len(), err)
+		}
+		if _, err := w.Write(text[window-1:]); err != nil || !bytes.Equal(buf.Bytes(), text) {
+			t.Errorf("expected all the text downstream once allowed, got %d (%v)", buf.Len(), err)
+		}
+
+		// the chunk that fills the detection window isnt written downstream if its rejected
+		buf.Reset()
+		w.Reset(&buf)
+		if _, err := w.Write(png); !errors.Is(err, ErrRejected) || buf.Len() != 0 {
+			t.Errorf("expected nothing written downstream for png, got %d (%v)", buf.Len(), err)
+		}
+
+		// short streams are written on close
+		buf.Reset()
+		w.Reset(&buf)
+		w.Write(text[:100])
+		if buf.Len() != 0 {
+			t.Errorf("expected nothing written downstream before close, got %d", buf.Len())
+		}
+		if err := w.Close(); err != nil || !bytes.Equal(buf.Bytes(), text[:100]) {
+			t.Errorf("expected short text downstream after close, got %d (%v)", buf.Len(), err)
+		}
+		buf.Reset()
+		w.Reset(&buf)
+		w.Write(png[:100])
+		if err := w.Close(); !errors.Is(err, ErrRejected) || buf.Len() != 0 {
+			t.Errorf("expected nothing written downstream for short png, got %d (%v)", buf.Len(), err)
+		}
+
+		// without allow or deny nothing is held
+		buf.Reset()
+		w = NewDefultOptions().NewWriter(&buf)
+		w.Write(text[:10])
+		if buf.Len() != 10 {
+			t.Errorf("expected 10 bytes downstream, got %d", buf.Len())
+		}
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewDefultOptions().UpdateMaxSize(100).NewWriter(&buf)
+		if _, err := w.Write(text[:60]); err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+		_, err := w.Write(text[:60])
+		var sizeErr *SizeError
+		if !errors.As(err, &sizeErr) || sizeErr.MaxSize != 100 {
+			t.Errorf("expected size error, got %v", err)
+		}
+		if buf.Len() != 60 {
+			t.Errorf("expected 60 bytes to be written, got %d", buf.Len())
+		}
+		if err := w.Close(); !errors.As(err, &sizeErr) {
+			t.Errorf("expected size error from close, got %v", err)
+		}
+		if _, err := w.Identifiers(); !errors.As(err, &sizeErr) {
+			t.Errorf("expected size error from identifiers, got %v", err)
+		}
+
+		w.Reset()
+		if _, err := w.Identify(context.Background(), bytes.NewReader(text[:100])); err != nil {
+			t.Errorf("expected nil error at the max size, got %v", err)
+		}
+		w.Reset()
+		if _, err := w.Identify(context.Background(), bytes.NewReader(text)); !errors.As(err, &sizeErr) {
+			t.Errorf("expected size error from identify, got %v", err)
+		}
+	})
+
+	t.Run("max entropy", func(t *testing.T) {
+		o := NewDefultOptions().UpdateIdentifiers(identifiers.NewChecksumOptions()).UpdateMaxEntropy(7)
+		w := o.NewWriter()
+		w.Write(random)
+		err := w.Close()
+		var entErr *EntropyError
+		if !errors.As(err, &entErr) || entErr.Entropy < 7 {
+			t.Errorf("expected entropy error, got %v", err)
+		}
+
+		w.Reset()
+		w.Write(text)
+		if err := w.Close(); err != nil {
+			t.Errorf("expected nil error for text, got %v", err)
+		}
+	})
+
+	t.Run("on filetype", func(t *testing.T) {
+		called := false
+		i := identifiers.NewDefultOptions().UpdateOnFiletype(func(filetype.Filetype) error {
+			called = true
+			return nil
+		})
+		w := NewDefultOptions().UpdateIdentifiers(i).UpdateDeny("text/*").NewWriter()
+		w.Write(png)
+		if !called {
+			t.Errorf("expected on filetype from the identifiers options to be called")
+		}
+	})
+}
@@ -3,8 +3,13 @@ package gzip
 import (
 	"compress/gzip"
 	"io"
+
+	"github.com/jonathongardner/fifo"
 )
 
+var _ fifo.ReadCloseReseter = (*Reader)(nil)
+
+// Reader is a gzip reader that can be reset back to the start of the stream
 type Reader struct {
 	gr *gzip.Reader
 	r  io.ReadSeeker
@@ -38,9 +43,8 @@ func (r *Reader) Close() error {
 
 // Reset resets the gzip reader to the beginning of the stream
 func (r *Reader) Reset() error {
-	r.r.Seek(0, io.SeekStart)
-	if err := r.gr.Reset(r.r); err != nil {
+	if _, err := r.r.Seek(0, io.SeekStart); err != nil {
 		return err
 	}
-	return nil
+	return r.gr.Reset(r.r)
 }
@@ -3,6 +3,7 @@ package gzip
 import (
 	"bytes"
 	"compress/gzip"
+	"errors"
 	"io"
 	"testing"
 )
@@ -71,3 +72,26 @@ func TestGzipReader(t *testing.T) {
 		t.Fatalf("expected %s but got %s after reset", string(toWrite), string(v3))
 	}
 }
+
+var errSeek = errors.New("seek failed")
+
+// failingSeeker is a reader that cant seek
+type failingSeeker struct {
+	*bytes.Reader
+}
+
+func (failingSeeker) Seek(int64, int) (int64, error) { return 0, errSeek }
+
+func TestGzipReaderResetError(t *testing.T) {
+	compressed, err := gzipCompress([]byte("Your String Here"))
+	if err != nil {
+		t.Fatalf("failed to compress data %v", err)
+	}
+	gzReader, err := NewReader(failingSeeker{bytes.NewReader(compressed)})
+	if err != nil {
+		t.Fatalf("failed to create new gzip reader %v", err)
+	}
+	if err := gzReader.Reset(); !errors.Is(err, errSeek) {
+		t.Errorf("expected seek error from reset, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package identifiers
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+var ErrUnknownAnalyzer = fmt.Errorf("unknown analyzer")
+
+// Analyzer is a writer that measures the data written to it, it can be
+// registered with RegisterAnalyzer and turned on by name in Options
+type Analyzer interface {
+	io.Writer
+	// Reset clears the analyzer so it can be used for a new stream
+	Reset()
+	// Result returns the measurement, it is added to Identifiers.Analyzers
+	Result() any
+}
+
+var (
+	analyzersMu sync.RWMutex
+	analyzers   = make(map[string]func() Analyzer)
+)
+
+// RegisterAnalyzer makes an analyzer available to Options by name
+// new is called for every Writer created, it panics if the name is already registered
+func RegisterAnalyzer(name string, new func() Analyzer) {
+	analyzersMu.Lock()
+	defer analyzersMu.Unlock()
+	if new == nil {
+		panic("identifiers: RegisterAnalyzer analyzer is nil")
+	}
+	if _, ok := analyzers[name]; ok {
+		panic("identifiers: RegisterAnalyzer called twice for analyzer " + name)
+	}
+	analyzers[name] = new
+}
+
+// RegisteredAnalyzers returns a sorted list of the names of the registered analyzers
+func RegisteredAnalyzers() []string {
+	analyzersMu.RLock()
+	defer analyzersMu.RUnlock()
+	names := make([]string, 0, len(analyzers))
+	for name := range analyzers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newAnalyzer creates a new analyzer from the registry
+func newAnalyzer(name string) (Analyzer, error) {
+	analyzersMu.RLock()
+	new, ok := analyzers[name]
+	analyzersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAnalyzer, name)
+	}
+	return new(), nil
+}
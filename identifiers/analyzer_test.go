@@ -0,0 +1,107 @@
+package identifiers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// lineCounter is an analyzer that counts new lines
+type lineCounter struct {
+	lines int
+}
+
+func (l *lineCounter) Write(p []byte) (int, error) {
+	l.lines += bytes.Count(p, []byte("\n"))
+	return len(p), nil
+}
+
+func (l *lineCounter) Reset() {
+	l.lines = 0
+}
+
+func (l *lineCounter) Result() any {
+	return l.lines
+}
+
+func init() {
+	RegisterAnalyzer("test-lines", func() Analyzer { return &lineCounter{} })
+}
+
+func TestAnalyzer(t *testing.T) {
+	toWrite := []byte("Something\ncool\n")
+
+	t.Run("registered", func(t *testing.T) {
+		found := false
+		for _, name := range RegisteredAnalyzers() {
+			if name == "test-lines" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected test-lines to be registered")
+		}
+	})
+
+	t.Run("results and reset", func(t *testing.T) {
+		w := NewChecksumOptions().AddAnalyzer("test-lines").NewWriter()
+		for i := 0; i < 2; i++ {
+			w.Reset()
+			if _, err := io.Copy(w, bytes.NewReader(toWrite)); err != nil {
+				t.Fatalf("failed to copy data %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("failed to close writer %v", err)
+			}
+			i, err := w.Identifiers()
+			if err != nil {
+				t.Fatalf("failed to get identifiers %v", err)
+			}
+			if i.Analyzers["test-lines"] != 2 {
+				t.Errorf("expected 2 lines, got %v", i.Analyzers["test-lines"])
+			}
+		}
+	})
+
+	t.Run("not turned on", func(t *testing.T) {
+		w := NewChecksumOptions().NewWriter()
+		w.Write(toWrite)
+		w.Close()
+		i, err := w.Identifiers()
+		if err != nil {
+			t.Fatalf("failed to get identifiers %v", err)
+		}
+		if i.Analyzers != nil {
+			t.Errorf("expected no analyzers, got %v", i.Analyzers)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		w := NewChecksumOptions().UpdateAnalyzers("test-nope").NewWriter()
+		if _, err := w.Write(toWrite); !errors.Is(err, ErrUnknownAnalyzer) {
+			t.Errorf("expected unknown analyzer error for write, got %v", err)
+		}
+		w.Close()
+		if _, err := w.Identifiers(); !errors.Is(err, ErrUnknownAnalyzer) {
+			t.Errorf("expected unknown analyzer error for identifiers, got %v", err)
+		}
+	})
+
+	t.Run("options are copied", func(t *testing.T) {
+		o1 := NewChecksumOptions().AddAnalyzer("test-lines")
+		o2 := o1.AddAnalyzer("test-nope")
+		if len(o1.Analyzers) != 1 || len(o2.Analyzers) != 2 {
+			t.Errorf("expected 1 and 2 analyzers, got %d and %d", len(o1.Analyzers), len(o2.Analyzers))
+		}
+	})
+
+	t.Run("register twice", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic registering twice")
+			}
+		}()
+		RegisterAnalyzer("test-lines", func() Analyzer { return &lineCounter{} })
+	})
+}
@@ -0,0 +1,128 @@
+package identifiers
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"fmt"
+)
+
+var (
+	ErrCheckpointUnsupported = fmt.Errorf("writer state cant be saved")
+	ErrCheckpointMismatch    = fmt.Errorf("checkpoint doesnt match the writer")
+)
+
+const checkpointVersion = 1
+
+// checkpoint is the saved state of a Writer, the state of each part by name
+type checkpoint struct {
+	Version int
+	Closed  bool
+	State   map[string][]byte
+}
+
+// parts returns everything in the writer that has state by name
+func (mw *Writer) parts() map[string]any {
+	toReturn := map[string]any{"cache": mw.cache}
+	if mw.tail != nil {
+		toReturn["tail"] = mw.tail
+	}
+	for name, h := range mw.namedHashes() {
+		toReturn[name] = h
+	}
+	if mw.ssdeep != nil {
+		toReturn["ssdeep"] = mw.ssdeep
+	}
+	if mw.tlsh != nil {
+		toReturn["tlsh"] = mw.tlsh
+	}
+	if mw.entropy != nil {
+		toReturn["entropy"] = mw.entropy
+	}
+	for name, a := range mw.analyzers {
+		toReturn["analyzer:"+name] = a
+	}
+	return toReturn
+}
+
+// MarshalBinary saves the state of the writer (hashes, entropy, size and the cached
+// data for the filetype) so it can be restored with UnmarshalBinary later. Analyzers
+// have to implement encoding.BinaryMarshaler. The state has the hmac state that
+// is derived from the key, so it should be kept as secret as the key
+func (mw *Writer) MarshalBinary() ([]byte, error) {
+	if mw.err != nil {
+		return nil, mw.err
+	}
+	if mw.parallel != nil {
+		if err := mw.parallel.wait(); err != nil {
+			return nil, err
+		}
+	}
+
+	c := checkpoint{Version: checkpointVersion, Closed: mw.closed, State: make(map[string][]byte)}
+	for name, p := range mw.parts() {
+		m, ok := p.(encoding.BinaryMarshaler)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrCheckpointUnsupported, name)
+		}
+		state, err := m.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", name, err)
+		}
+		c.State[name] = state
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(c); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the state saved with MarshalBinary, the writer has to be
+// created with the same options (and hmac key). Once restored writing carries on
+// where the saved writer stopped, giving the same Identifiers as one uninterrupted pass.
+// Extra writers are kept unless the writer is closed, then its reset without them
+func (mw *Writer) UnmarshalBinary(b []byte) error {
+	if mw.err != nil {
+		return mw.err
+	}
+	var c checkpoint
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&c); err != nil {
+		return fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	if c.Version != checkpointVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrCheckpointMismatch, c.Version)
+	}
+	parts := mw.parts()
+	if len(parts) != len(c.State) {
+		return fmt.Errorf("%w: expected %d parts, got %d", ErrCheckpointMismatch, len(parts), len(c.State))
+	}
+	for name, p := range parts {
+		if _, ok := c.State[name]; !ok {
+			return fmt.Errorf("%w: missing %s", ErrCheckpointMismatch, name)
+		}
+		if _, ok := p.(encoding.BinaryUnmarshaler); !ok {
+			return fmt.Errorf("%w: %s", ErrCheckpointUnsupported, name)
+		}
+	}
+
+	if mw.closed {
+		mw.Reset()
+	} else if mw.parallel != nil {
+		// make sure nothing is being written while restoring
+		mw.parallel.wait()
+	}
+	mw.writeErr = nil
+	for name, p := range parts {
+		if err := p.(encoding.BinaryUnmarshaler).UnmarshalBinary(c.State[name]); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", name, err)
+		}
+	}
+	// the restored stream already called onFiletype if the file type was known
+	mw.filetypeDone = mw.filetypeReady()
+	if c.Closed {
+		return mw.Close()
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package identifiers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	data := make([]byte, 300000)
+	rand.New(rand.NewSource(1)).Read(data)
+	// text at the start so the filetype comes from the cached data
+	copy(data, bytes.Repeat([]byte("Something cool\n"), 1000))
+
+	o := allOptions().UpdateAnalyzers()
+	exp := identify(t, o.NewWriter(), data)
+
+	t.Run("resume", func(t *testing.T) {
+		for _, concurrent := range []bool{false, true} {
+			o := o.UpdateConcurrent(concurrent)
+			for _, cut := range []int{0, 7, 3000, 150001} {
+				w := o.NewWriter()
+				w.Write(data[:cut])
+				state, err := w.MarshalBinary()
+				if err != nil {
+					t.Fatalf("expected nil error for marshal, got %v", err)
+				}
+				// keep writing to the old one to make sure the state isnt shared
+				w.Write(data[:cut])
+				w.Close()
+
+				restored := o.NewWriter()
+				if err := restored.UnmarshalBinary(state); err != nil {
+					t.Fatalf("expected nil error for unmarshal, got %v", err)
+				}
+				restored.Write(data[cut:])
+				if err := restored.Close(); err != nil {
+					t.Fatalf("failed to close writer %v", err)
+				}
+				act, err := restored.Identifiers()
+				if err != nil {
+					t.Fatalf("failed to get identifiers %v", err)
+				}
+				if !reflect.DeepEqual(act, exp) {
+					t.Errorf("expected %+v for cut %d (concurrent %v), got %+v", exp, cut, concurrent, act)
+				}
+			}
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		w := o.NewWriter()
+		w.Write(data)
+		w.Close()
+		state, err := w.MarshalBinary()
+		if err != nil {
+			t.Fatalf("expected nil error for marshal, got %v", err)
+		}
+		restored := o.NewWriter()
+		if err := restored.UnmarshalBinary(state); err != nil {
+			t.Fatalf("expected nil error for unmarshal, got %v", err)
+		}
+		if act, err := restored.Identifiers(); err != nil || !reflect.DeepEqual(act, exp) {
+			t.Errorf("expected %+v, got %+v (%v)", exp, act, err)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		state, _ := o.NewWriter().MarshalBinary()
+		err := NewDefultOptions().NewWriter().UnmarshalBinary(state)
+		if !errors.Is(err, ErrCheckpointMismatch) {
+			t.Errorf("expected mismatch error, got %v", err)
+		}
+		if err := o.NewWriter().UnmarshalBinary([]byte("junk")); err == nil {
+			t.Errorf("expected error for junk, got nil")
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := NewChecksumOptions().AddAnalyzer("test-lines").NewWriter().MarshalBinary()
+		if !errors.Is(err, ErrCheckpointUnsupported) {
+			t.Errorf("expected unsupported error, got %v", err)
+		}
+	})
+}
+
+func TestKeyedHash(t *testing.T) {
+	for _, key := range [][]byte{[]byte("secret"), bytes.Repeat([]byte("long key"), 20)} {
+		exp := hmac.New(sha256.New, key)
+		exp.Write([]byte("Something cool"))
+
+		act := newKeyedHash(sha256.New, key)
+		act.Write([]byte("Something"))
+		state, _ := act.MarshalBinary()
+		act.Write([]byte("junk"))
+		act.UnmarshalBinary(state)
+		act.Write([]byte(" cool"))
+		if !hmac.Equal(act.Sum(nil), exp.Sum(nil)) {
+			t.Errorf("expected %s, got %s", hex.EncodeToString(exp.Sum(nil)), hex.EncodeToString(act.Sum(nil)))
+		}
+	}
+}
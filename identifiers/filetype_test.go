@@ -0,0 +1,170 @@
+package identifiers
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jonathongardner/fifo/filetype"
+)
+
+func TestFiletypeCallback(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, filetype.MaxBytesFileDetect())...)
+	errDenied := errors.New("denied")
+
+	t.Run("window full", func(t *testing.T) {
+		var called []filetype.Filetype
+		w := NewChecksumOptions().UpdateOnFiletype(func(ft filetype.Filetype) error {
+			called = append(called, ft)
+			return nil
+		}).NewWriter()
+
+		w.Write(png[:100])
+		if len(called) != 0 {
+			t.Errorf("expected no calls before the window is full, got %v", called)
+		}
+		if _, ok := w.Filetype(); ok {
+			t.Errorf("expected filetype to not be ready")
+		}
+		w.Write(png[100:])
+		if len(called) != 1 || called[0].Mimetype != "image/png" {
+			t.Errorf("expected one call with image/png, got %v", called)
+		}
+		if ft, ok := w.Filetype(); !ok || ft.Mimetype != "image/png" {
+			t.Errorf("expected image/png mid stream, got %v %v", ft, ok)
+		}
+		w.Write(png)
+		w.Close()
+		if len(called) != 1 {
+			t.Errorf("expected only one call, got %v", called)
+		}
+
+		// short streams are called on close
+		w.Reset()
+		w.Write([]byte("Something cool"))
+		if len(called) != 1 {
+			t.Errorf("expected no call before close, got %v", called)
+		}
+		w.Close()
+		if len(called) != 2 || called[1].Mimetype != "text/plain; charset=utf-8" {
+			t.Errorf("expected call with text/plain on close, got %v", called)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		w := NewChecksumOptions().UpdateOnFiletype(func(ft filetype.Filetype) error {
+			if ft.Is("image/png") {
+				return errDenied
+			}
+			return nil
+		}).NewWriter()
+
+		if _, err := w.Write(png); !errors.Is(err, errDenied) {
+			t.Errorf("expected denied error from write, got %v", err)
+		}
+		if _, err := w.Write(png); !errors.Is(err, errDenied) {
+			t.Errorf("expected denied error from next write, got %v", err)
+		}
+		w.Close()
+		if _, err := w.Identifiers(); !errors.Is(err, errDenied) {
+			t.Errorf("expected denied error from identifiers, got %v", err)
+		}
+
+		w.Reset()
+		if _, err := w.Write([]byte("Something cool")); err != nil {
+			t.Errorf("expected nil error after reset, got %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("expected nil error from close, got %v", err)
+		}
+
+		w.Reset()
+		w.Write(png[:10])
+		if err := w.Close(); !errors.Is(err, errDenied) {
+			t.Errorf("expected denied error from close, got %v", err)
+		}
+	})
+
+	t.Run("filetype", func(t *testing.T) {
+		w := NewDefultOptions().NewWriter()
+		w.Write(bytes.Repeat([]byte("a"), 10))
+		if _, ok := w.Filetype(); ok {
+			t.Errorf("expected filetype to not be ready")
+		}
+		w.Close()
+		if ft, ok := w.Filetype(); !ok || ft.Mimetype != "text/plain; charset=utf-8" {
+			t.Errorf("expected text/plain once closed, got %v %v", ft, ok)
+		}
+
+		w = NewChecksumOptions().NewWriter()
+		w.Write(png)
+		if _, ok := w.Filetype(); ok {
+			t.Errorf("expected no filetype when detection is off")
+		}
+	})
+}
+
+func TestTrailerFiletype(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, _ := zw.Create("file.txt")
+	f.Write(bytes.Repeat([]byte("Something cool\n"), 100))
+	zw.Close()
+	zipped := buf.Bytes()
+	exe := append([]byte("MZ"), make([]byte, 2*filetype.MaxBytesFileDetect())...)
+	sfx := append(append([]byte{}, exe...), zipped...)
+
+	o := NewDefultOptions().UpdateTailSize(1024)
+	w := o.NewWriter()
+	i := identify(t, w, sfx)
+	if i.Filetype.Mimetype != "application/vnd.microsoft.portable-executable" {
+		t.Errorf("expected exe, got %v", i.Filetype)
+	}
+	if i.Trailer == nil || i.Trailer.Mimetype != "application/zip" {
+		t.Errorf("expected zip trailer, got %v", i.Trailer)
+	}
+
+	if i := identify(t, w, zipped); i.Filetype.Mimetype != "application/zip" || i.Trailer != nil {
+		t.Errorf("expected zip without a trailer, got %v %v", i.Filetype, i.Trailer)
+	}
+	// a docx (or jar) is a zip so it doesnt have a zip trailer
+	var docxBuf bytes.Buffer
+	zw = zip.NewWriter(&docxBuf)
+	for _, name := range []string{"[Content_Types].xml", "word/document.xml"} {
+		f, _ := zw.Create(name)
+		f.Write([]byte("<xml/>"))
+	}
+	zw.Close()
+	docx := "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	if i := identify(t, w, docxBuf.Bytes()); i.Filetype.Mimetype != docx || i.Trailer != nil {
+		t.Errorf("expected docx without a trailer, got %v %v", i.Filetype, i.Trailer)
+	}
+	if i := identify(t, w, exe); i.Trailer != nil {
+		t.Errorf("expected no trailer, got %v", i.Trailer)
+	}
+	if i := identify(t, NewDefultOptions().NewWriter(), sfx); i.Trailer != nil {
+		t.Errorf("expected no trailer when the tail is off, got %v", i.Trailer)
+	}
+
+	t.Run("checkpoint", func(t *testing.T) {
+		w := o.UpdateConcurrent(true).NewWriter()
+		w.Write(sfx[:len(sfx)-100])
+		state, err := w.MarshalBinary()
+		w.Close()
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+
+		restored := o.NewWriter()
+		if err := restored.UnmarshalBinary(state); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		restored.Write(sfx[len(sfx)-100:])
+		restored.Close()
+		if act, err := restored.Identifiers(); err != nil || !reflect.DeepEqual(act, i) {
+			t.Errorf("expected %+v, got %+v (%v)", i, act, err)
+		}
+	})
+}
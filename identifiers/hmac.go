@@ -0,0 +1,122 @@
+package identifiers
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding"
+	"fmt"
+	"hash"
+)
+
+var ErrMissingHmacKey = fmt.Errorf("hmac key is not set")
+
+// KeyProvider looks up the key used for hmac by its id
+type KeyProvider interface {
+	Key(id string) ([]byte, error)
+}
+
+// KeyProviderFunc is a function that implements KeyProvider
+type KeyProviderFunc func(id string) ([]byte, error)
+
+// Key calls f(id)
+func (f KeyProviderFunc) Key(id string) ([]byte, error) {
+	return f(id)
+}
+
+// hmacKey returns the key from the options, looking it up with the provider if needed
+func (o Options) hmacKey() ([]byte, error) {
+	if o.HmacKey != nil {
+		return o.HmacKey, nil
+	}
+	if o.HmacKeyProvider == nil {
+		return nil, ErrMissingHmacKey
+	}
+	key, err := o.HmacKeyProvider.Key(o.HmacKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hmac key %s: %w", o.HmacKeyID, err)
+	}
+	return key, nil
+}
+
+// newHmacs creates the hmac hashes turned on in the options
+// the key is only kept inside the hashes, hmac.Reset goes back to the keyed state
+func newHmacs(o Options) (hash.Hash, hash.Hash, error) {
+	if !o.HmacSha256 && !o.HmacSha512 {
+		return nil, nil, nil
+	}
+	key, err := o.hmacKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	var hmacSha256, hmacSha512 hash.Hash
+	if o.HmacSha256 {
+		hmacSha256 = newKeyedHash(sha256.New, key)
+	}
+	if o.HmacSha512 {
+		hmacSha512 = newKeyedHash(sha512.New, key)
+	}
+	return hmacSha256, hmacSha512, nil
+}
+
+// keyedHash is hmac (RFC 2104) that can be marshaled like the stdlib hashes so
+// it can be checkpointed, crypto/hmac cant be. The marshaled state is derived
+// from the key so it should be kept as secret as the key
+type keyedHash struct {
+	inner hash.Hash
+	outer hash.Hash
+	ipad  []byte
+	opad  []byte
+}
+
+func newKeyedHash(h func() hash.Hash, key []byte) *keyedHash {
+	kh := &keyedHash{inner: h(), outer: h()}
+	blockSize := kh.inner.BlockSize()
+	if len(key) > blockSize {
+		// long keys are hashed first
+		kh.outer.Write(key)
+		key = kh.outer.Sum(nil)
+	}
+	kh.ipad = make([]byte, blockSize)
+	kh.opad = make([]byte, blockSize)
+	copy(kh.ipad, key)
+	copy(kh.opad, key)
+	for i := range kh.ipad {
+		kh.ipad[i] ^= 0x36
+		kh.opad[i] ^= 0x5c
+	}
+	kh.Reset()
+	return kh
+}
+
+func (kh *keyedHash) Write(p []byte) (int, error) {
+	return kh.inner.Write(p)
+}
+
+func (kh *keyedHash) Sum(b []byte) []byte {
+	in := kh.inner.Sum(nil)
+	kh.outer.Reset()
+	kh.outer.Write(kh.opad)
+	kh.outer.Write(in)
+	return kh.outer.Sum(b)
+}
+
+func (kh *keyedHash) Reset() {
+	kh.inner.Reset()
+	kh.inner.Write(kh.ipad)
+}
+
+func (kh *keyedHash) Size() int {
+	return kh.outer.Size()
+}
+
+func (kh *keyedHash) BlockSize() int {
+	return kh.inner.BlockSize()
+}
+
+func (kh *keyedHash) MarshalBinary() ([]byte, error) {
+	return kh.inner.(encoding.BinaryMarshaler).MarshalBinary()
+}
+
+func (kh *keyedHash) UnmarshalBinary(b []byte) error {
+	return kh.inner.(encoding.BinaryUnmarshaler).UnmarshalBinary(b)
+}
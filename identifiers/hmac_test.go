@@ -0,0 +1,89 @@
+package identifiers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestHmacWriter(t *testing.T) {
+	toWrite := []byte("Something cool")
+	key := []byte("secret")
+	expSha256 := "1f9942bd8e83cd7bf57e5738511d9d8a89d883b3c7c45bd02b84a82ccc16f3a1"
+	expSha512 := "cb526d750dc5724970e28aa19f036648d4c53f60627a3363bcd66fe6eae2684f179e640df30354bf10352d0a99e2c157cea341474608ece3c2c7a3bbcf8ce0f1"
+
+	assertHmac := func(t *testing.T, w *Writer, keyID string) {
+		t.Helper()
+		// run twice to make sure reset keeps the key
+		for i := 0; i < 2; i++ {
+			w.Reset()
+			if _, err := io.Copy(w, bytes.NewReader(toWrite)); err != nil {
+				t.Fatalf("failed to copy data %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("failed to close writer %v", err)
+			}
+			act, err := w.Identifiers()
+			if err != nil {
+				t.Fatalf("failed to get identifiers %v", err)
+			}
+			if act.HmacSha256 != expSha256 {
+				t.Errorf("hmac sha256 mismatch, expected %v, got %v", expSha256, act.HmacSha256)
+			}
+			if act.HmacSha512 != expSha512 {
+				t.Errorf("hmac sha512 mismatch, expected %v, got %v", expSha512, act.HmacSha512)
+			}
+			if act.HmacKeyID != keyID {
+				t.Errorf("hmac key id mismatch, expected %v, got %v", keyID, act.HmacKeyID)
+			}
+
+			data, err := json.Marshal(act)
+			if err != nil {
+				t.Fatalf("failed to marshal identifiers %v", err)
+			}
+			if bytes.Contains(data, key) {
+				t.Errorf("expected key to not be in json %s", string(data))
+			}
+		}
+	}
+
+	o := NewChecksumOptions().UpdateHmacSha256(true).UpdateHmacSha512(true)
+
+	t.Run("key", func(t *testing.T) {
+		assertHmac(t, o.UpdateHmacKey("", key).NewWriter(), "")
+	})
+
+	t.Run("key provider", func(t *testing.T) {
+		provider := KeyProviderFunc(func(id string) ([]byte, error) {
+			if id != "key-1" {
+				return nil, errors.New("unknown key")
+			}
+			return key, nil
+		})
+		assertHmac(t, o.UpdateHmacKeyProvider("key-1", provider).NewWriter(), "key-1")
+
+		w := o.UpdateHmacKeyProvider("key-2", provider).NewWriter()
+		if _, err := w.Write(toWrite); err == nil {
+			t.Errorf("expected error for unknown key")
+		}
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		w := o.NewWriter()
+		if _, err := w.Write(toWrite); !errors.Is(err, ErrMissingHmacKey) {
+			t.Errorf("expected missing key error, got %v", err)
+		}
+	})
+
+	t.Run("options json", func(t *testing.T) {
+		data, err := json.Marshal(o.UpdateHmacKey("key-1", key))
+		if err != nil {
+			t.Fatalf("failed to marshal options %v", err)
+		}
+		if bytes.Contains(data, key) {
+			t.Errorf("expected key to not be in json %s", string(data))
+		}
+	})
+}
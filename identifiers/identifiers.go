@@ -3,24 +3,44 @@ package identifiers
 import (
 	"encoding/hex"
 	"fmt"
+	"hash"
 
+	"github.com/jonathongardner/fifo/entropy"
 	"github.com/jonathongardner/fifo/filetype"
 )
 
 var ErrWriterNotClosed = fmt.Errorf("writer is not closed")
 
 type Identifiers struct {
-	Md5      string            `json:"md5,omitempty"`
-	Sha1     string            `json:"sha1,omitempty"`
-	Sha256   string            `json:"sha256,omitempty"`
-	Sha512   string            `json:"sha512,omitempty"`
-	Entropy  float64           `json:"entropy,omitempty"`
-	Filetype filetype.Filetype `json:"filetype,omitempty"`
-	Size     int64             `json:"size,omitempty"`
+	Md5          string                  `json:"md5,omitempty"`
+	Sha1         string                  `json:"sha1,omitempty"`
+	Sha256       string                  `json:"sha256,omitempty"`
+	Sha512       string                  `json:"sha512,omitempty"`
+	Sha224       string                  `json:"sha224,omitempty"`
+	Sha384       string                  `json:"sha384,omitempty"`
+	Sha512_256   string                  `json:"sha512_256,omitempty"`
+	Crc32        string                  `json:"crc32,omitempty"`
+	Crc64        string                  `json:"crc64,omitempty"`
+	Adler32      string                  `json:"adler32,omitempty"`
+	Fnv32a       string                  `json:"fnv32a,omitempty"`
+	Fnv64a       string                  `json:"fnv64a,omitempty"`
+	HmacSha256   string                  `json:"hmac_sha256,omitempty"`
+	HmacSha512   string                  `json:"hmac_sha512,omitempty"`
+	HmacKeyID    string                  `json:"hmac_key_id,omitempty"` // id of the key used for the hmacs, never the key
+	Ssdeep       string                  `json:"ssdeep,omitempty"`
+	Tlsh         string                  `json:"tlsh,omitempty"` // "not enough data" if the input is too small for a digest
+	Entropy      float64                 `json:"entropy,omitempty"`
+	EntropyStats *entropy.Stats          `json:"entropy_stats,omitempty"`
+	Filetype     filetype.Filetype       `json:"filetype,omitempty"`
+	Trailer      *filetype.Filetype      `json:"trailer_filetype,omitempty"`   // if the end of the stream is a different file type
+	Mismatch     bool                    `json:"extension_mismatch,omitempty"` // set by CheckFilename
+	Class        *entropy.Classification `json:"classification,omitempty"`
+	Size         int64                   `json:"size,omitempty"`
+	Analyzers    map[string]any          `json:"analyzers,omitempty"` // results of the registered analyzers by name
 }
 
 // Identifiers returns the info of the writer that "identifies" the data
-// the md5, sha1, sha256, sha512, entropy, file type, and size
+// the md5, sha1, sha256, sha512 (and other checksums), entropy, file type, and size
 // it returns an empty string if the hash is not calculated
 // it returns 0 if the entropy is not calculated
 // it returns nil if the file type is not calculated
@@ -28,26 +48,71 @@ func (mw *Writer) Identifiers() (Identifiers, error) {
 	if !mw.closed {
 		return Identifiers{}, ErrWriterNotClosed
 	}
-
-	toReturn := Identifiers{}
-	if mw.md5 != nil {
-		toReturn.Md5 = hex.EncodeToString(mw.md5.Sum(nil))
+	if mw.err != nil {
+		return Identifiers{}, mw.err
 	}
-	if mw.sha1 != nil {
-		toReturn.Sha1 = hex.EncodeToString(mw.sha1.Sum(nil))
+	if mw.writeErr != nil {
+		return Identifiers{}, mw.writeErr
 	}
-	if mw.sha256 != nil {
-		toReturn.Sha256 = hex.EncodeToString(mw.sha256.Sum(nil))
+
+	toReturn := Identifiers{}
+	toReturn.Md5 = sum(mw.md5)
+	toReturn.Sha1 = sum(mw.sha1)
+	toReturn.Sha256 = sum(mw.sha256)
+	toReturn.Sha512 = sum(mw.sha512)
+	toReturn.Sha224 = sum(mw.sha224)
+	toReturn.Sha384 = sum(mw.sha384)
+	toReturn.Sha512_256 = sum(mw.sha512_256)
+	toReturn.Crc32 = sum(mw.crc32)
+	toReturn.Crc64 = sum(mw.crc64)
+	toReturn.Adler32 = sum(mw.adler32)
+	toReturn.Fnv32a = sum(mw.fnv32a)
+	toReturn.Fnv64a = sum(mw.fnv64a)
+	toReturn.HmacSha256 = sum(mw.hmacSha256)
+	toReturn.HmacSha512 = sum(mw.hmacSha512)
+	toReturn.HmacKeyID = mw.hmacKeyID
+	if mw.ssdeep != nil {
+		toReturn.Ssdeep = mw.ssdeep.Sum()
 	}
-	if mw.sha512 != nil {
-		toReturn.Sha512 = hex.EncodeToString(mw.sha512.Sum(nil))
+	if mw.tlsh != nil {
+		toReturn.Tlsh = mw.tlsh.Sum()
 	}
 	if mw.entropy != nil {
 		toReturn.Entropy = mw.entropy.Entropy()
+		if mw.entStats {
+			stats := mw.entropy.Stats()
+			toReturn.EntropyStats = &stats
+		}
+		if mw.classify {
+			class := mw.entropy.Classify()
+			toReturn.Class = &class
+		}
 	}
 	if mw.ftype {
-		toReturn.Filetype = filetype.NewFiletypeFromCached(mw.cache)
+		toReturn.Filetype = mw.detectFiletype()
+		toReturn.Trailer = mw.trailerFiletype(toReturn.Filetype)
+	}
+	for name, a := range mw.analyzers {
+		if toReturn.Analyzers == nil {
+			toReturn.Analyzers = make(map[string]any)
+		}
+		toReturn.Analyzers[name] = a.Result()
 	}
 	toReturn.Size = mw.cache.Size()
 	return toReturn, nil
 }
+
+// CheckFilename sets Mismatch if the file type was detected and the extension of
+// name doesnt agree with it (i.e. an executable named invoice.pdf), see filetype.MatchesFilename
+func (i *Identifiers) CheckFilename(name string) {
+	i.Mismatch = i.Filetype.Mimetype != "" && !filetype.MatchesFilename(name, i.Filetype)
+}
+
+// sum returns the hex encoded hash, empty if the hash isnt turned on
+// checksums like crc32 are big endian so they match the usual hex output
+func sum(h hash.Hash) string {
+	if h == nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,89 @@
+package identifiers
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Progress is how much of a stream has been identified so far
+type Progress struct {
+	Bytes          int64
+	Elapsed        time.Duration
+	BytesPerSecond float64
+}
+
+// ProgressFunc is called with the progress while identifying
+type ProgressFunc func(Progress)
+
+// Identify identifies r using the default options, see Writer.Identify
+func Identify(ctx context.Context, r io.Reader) (Identifiers, error) {
+	return NewDefultOptions().Identify(ctx, r)
+}
+
+// Identify identifies r with a new writer using the options, see Writer.Identify
+func (o Options) Identify(ctx context.Context, r io.Reader) (Identifiers, error) {
+	return o.NewWriter().Identify(ctx, r)
+}
+
+// Identify copies r into the writer, closes it and returns the identifiers (call
+// Reset first to reuse a writer). It stops with ctx.Err() once ctx is cancelled,
+// which is checked before every read. The progress function from the options is
+// called every progress interval (checked after every read) and once r is done
+func (mw *Writer) Identify(ctx context.Context, r io.Reader) (Identifiers, error) {
+	pr := &progressReader{
+		ctx:      ctx,
+		r:        r,
+		progress: mw.progress,
+		interval: mw.progressInterval,
+		start:    time.Now(),
+	}
+	pr.last = pr.start
+	_, err := io.Copy(mw, pr)
+	closeErr := mw.Close()
+	if err != nil {
+		return Identifiers{}, err
+	}
+	if closeErr != nil {
+		return Identifiers{}, closeErr
+	}
+	pr.report(time.Now())
+	return mw.Identifiers()
+}
+
+// progressReader stops reading once the context is cancelled and reports the progress
+type progressReader struct {
+	ctx      context.Context
+	r        io.Reader
+	progress ProgressFunc
+	interval time.Duration
+	start    time.Time
+	last     time.Time
+	bytes    int64
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	if err := pr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := pr.r.Read(p)
+	pr.bytes += int64(n)
+	if pr.progress != nil {
+		if now := time.Now(); now.Sub(pr.last) >= pr.interval {
+			pr.report(now)
+		}
+	}
+	return n, err
+}
+
+func (pr *progressReader) report(now time.Time) {
+	if pr.progress == nil {
+		return
+	}
+	pr.last = now
+	p := Progress{Bytes: pr.bytes, Elapsed: now.Sub(pr.start)}
+	if seconds := p.Elapsed.Seconds(); seconds > 0 {
+		p.BytesPerSecond = float64(p.Bytes) / seconds
+	}
+	pr.progress(p)
+}
@@ -0,0 +1,100 @@
+package identifiers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// cancelReader cancels the context once after bytes have been read
+type cancelReader struct {
+	r      *bytes.Reader
+	after  int
+	cancel context.CancelFunc
+	read   int
+}
+
+func (c *cancelReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p[:min(len(p), 10)])
+	c.read += n
+	if c.read >= c.after {
+		c.cancel()
+	}
+	return n, err
+}
+
+func TestIdentify(t *testing.T) {
+	toWrite := bytes.Repeat([]byte("Something cool\n"), 100)
+
+	w := NewWriter()
+	w.Write(toWrite)
+	w.Close()
+	exp, _ := w.Identifiers()
+
+	t.Run("identify", func(t *testing.T) {
+		act, err := Identify(context.Background(), bytes.NewReader(toWrite))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !reflect.DeepEqual(act, exp) {
+			t.Errorf("expected %+v, got %+v", exp, act)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := Identify(ctx, bytes.NewReader(toWrite)); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected canceled error, got %v", err)
+		}
+
+		ctx, cancel = context.WithCancel(context.Background())
+		defer cancel()
+		r := &cancelReader{r: bytes.NewReader(toWrite), after: 100, cancel: cancel}
+		_, err := NewDefultOptions().UpdateConcurrent(true).Identify(ctx, r)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected canceled error, got %v", err)
+		}
+		if r.read != 100 {
+			t.Errorf("expected to stop reading at 100, got %d", r.read)
+		}
+	})
+
+	t.Run("progress", func(t *testing.T) {
+		var progress []Progress
+		o := NewDefultOptions().UpdateProgress(0, func(p Progress) {
+			progress = append(progress, p)
+		})
+		act, err := o.Identify(context.Background(), iotest.OneByteReader(bytes.NewReader(toWrite)))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !reflect.DeepEqual(act, exp) {
+			t.Errorf("expected %+v, got %+v", exp, act)
+		}
+		// every read (including the one returning EOF) and once done
+		if len(progress) != len(toWrite)+2 {
+			t.Fatalf("expected %d progress calls, got %d", len(toWrite)+2, len(progress))
+		}
+		for i := 1; i < len(progress); i++ {
+			if progress[i].Bytes < progress[i-1].Bytes || progress[i].Elapsed < progress[i-1].Elapsed {
+				t.Errorf("expected progress to increase, got %+v then %+v", progress[i-1], progress[i])
+			}
+		}
+		if last := progress[len(progress)-1]; last.Bytes != int64(len(toWrite)) {
+			t.Errorf("expected %d bytes, got %d", len(toWrite), last.Bytes)
+		}
+
+		// long interval only reports once done
+		progress = nil
+		o = o.UpdateProgress(time.Hour, o.Progress)
+		o.Identify(context.Background(), bytes.NewReader(toWrite))
+		if len(progress) != 1 || progress[0].Bytes != int64(len(toWrite)) {
+			t.Errorf("expected one progress call with %d bytes, got %+v", len(toWrite), progress)
+		}
+	})
+}
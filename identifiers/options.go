@@ -2,19 +2,43 @@ package identifiers
 
 import (
 	"io"
+	"time"
 
 	"github.com/jonathongardner/fifo/filetype"
 )
 
 // Options is a struct that contains options for the Writer
 type Options struct {
-	Md5       bool
-	Sha1      bool
-	Sha256    bool
-	Sha512    bool
-	Entropy   bool
-	Filetype  bool
-	CacheSize int64 // use 0 for no cache
+	Md5              bool
+	Sha1             bool
+	Sha256           bool
+	Sha512           bool
+	Sha224           bool
+	Sha384           bool
+	Sha512_256       bool
+	Crc32            bool // IEEE polynomial
+	Crc64            bool // ECMA polynomial
+	Adler32          bool
+	Fnv32a           bool
+	Fnv64a           bool
+	HmacSha256       bool
+	HmacSha512       bool
+	HmacKey          []byte      `json:"-"` // if nil the key is looked up with HmacKeyProvider
+	HmacKeyID        string      // id of the key, added to the identifiers
+	HmacKeyProvider  KeyProvider `json:"-"`
+	Ssdeep           bool
+	Tlsh             bool
+	Entropy          bool
+	EntropyStats     bool // randomness tests, also calculates the entropy
+	Classify         bool // class of the content (plaintext, compressed...), also calculates the entropy
+	Filetype         bool
+	CacheSize        int64                         // use 0 for no cache
+	TailSize         int64                         // bytes kept from the end to detect the trailer file type, use 0 for none
+	Concurrent       bool                          // calculate each hash in its own goroutine, once written to Close has to be called
+	OnFiletype       func(filetype.Filetype) error `json:"-"` // called once the file type is known, see Writer.Filetype
+	Progress         ProgressFunc                  `json:"-"` // called while identifying, see Writer.Identify
+	ProgressInterval time.Duration                 // how often progress is called, 0 for every read
+	Analyzers        []string                      // names of registered analyzers to run, see RegisterAnalyzer
 }
 
 // NewDefultOptions creates a new Options struct with default values
@@ -80,12 +104,132 @@ func (o Options) UpdateSha512(sha512 bool) Options {
 	return o
 }
 
+// UpdateSha224 updates the sha224 option of the Options struct
+func (o Options) UpdateSha224(sha224 bool) Options {
+	o.Sha224 = sha224
+	return o
+}
+
+// UpdateSha384 updates the sha384 option of the Options struct
+func (o Options) UpdateSha384(sha384 bool) Options {
+	o.Sha384 = sha384
+	return o
+}
+
+// UpdateSha512_256 updates the sha512/256 option of the Options struct
+func (o Options) UpdateSha512_256(sha512_256 bool) Options {
+	o.Sha512_256 = sha512_256
+	return o
+}
+
+// UpdateCrc32 updates the crc32 option of the Options struct
+func (o Options) UpdateCrc32(crc32 bool) Options {
+	o.Crc32 = crc32
+	return o
+}
+
+// UpdateCrc64 updates the crc64 option of the Options struct
+func (o Options) UpdateCrc64(crc64 bool) Options {
+	o.Crc64 = crc64
+	return o
+}
+
+// UpdateAdler32 updates the adler32 option of the Options struct
+func (o Options) UpdateAdler32(adler32 bool) Options {
+	o.Adler32 = adler32
+	return o
+}
+
+// UpdateFnv32a updates the fnv-1a 32 bit option of the Options struct
+func (o Options) UpdateFnv32a(fnv32a bool) Options {
+	o.Fnv32a = fnv32a
+	return o
+}
+
+// UpdateFnv64a updates the fnv-1a 64 bit option of the Options struct
+func (o Options) UpdateFnv64a(fnv64a bool) Options {
+	o.Fnv64a = fnv64a
+	return o
+}
+
+// UpdateHmacSha256 updates the hmac sha256 option of the Options struct
+func (o Options) UpdateHmacSha256(hmacSha256 bool) Options {
+	o.HmacSha256 = hmacSha256
+	return o
+}
+
+// UpdateHmacSha512 updates the hmac sha512 option of the Options struct
+func (o Options) UpdateHmacSha512(hmacSha512 bool) Options {
+	o.HmacSha512 = hmacSha512
+	return o
+}
+
+// UpdateHmacKey updates the hmac key and key id of the Options struct
+// the id is optional, its only used to know which key was used
+func (o Options) UpdateHmacKey(id string, key []byte) Options {
+	o.HmacKeyID = id
+	o.HmacKey = append([]byte{}, key...)
+	return o
+}
+
+// UpdateHmacKeyProvider updates the hmac key id and the provider used to look up the key
+func (o Options) UpdateHmacKeyProvider(id string, provider KeyProvider) Options {
+	o.HmacKeyID = id
+	o.HmacKey = nil
+	o.HmacKeyProvider = provider
+	return o
+}
+
+// UpdateSsdeep updates the ssdeep fuzzy hash option of the Options struct
+func (o Options) UpdateSsdeep(ssdeep bool) Options {
+	o.Ssdeep = ssdeep
+	return o
+}
+
+// UpdateTlsh updates the tlsh locality sensitive hash option of the Options struct
+func (o Options) UpdateTlsh(tlsh bool) Options {
+	o.Tlsh = tlsh
+	return o
+}
+
+// UpdateConcurrent updates the concurrent option of the Options struct
+func (o Options) UpdateConcurrent(concurrent bool) Options {
+	o.Concurrent = concurrent
+	return o
+}
+
+// UpdateOnFiletype updates the function called as soon as the file type is known,
+// an error from it stops the writer (i.e. to reject a file type)
+func (o Options) UpdateOnFiletype(onFiletype func(filetype.Filetype) error) Options {
+	o.OnFiletype = onFiletype
+	return o
+}
+
+// UpdateProgress updates the progress function and how often its called
+func (o Options) UpdateProgress(interval time.Duration, progress ProgressFunc) Options {
+	o.ProgressInterval = interval
+	o.Progress = progress
+	return o
+}
+
 // UpdateEntropy updates the entropy option of the Options struct
 func (o Options) UpdateEntropy(entropy bool) Options {
 	o.Entropy = entropy
 	return o
 }
 
+// UpdateEntropyStats updates the randomness tests option of the Options struct
+func (o Options) UpdateEntropyStats(stats bool) Options {
+	o.EntropyStats = stats
+	return o
+}
+
+// UpdateClassify updates the content classification option of the Options struct
+func (o Options) UpdateClassify(classify bool) Options {
+	o.Classify = classify
+	return o
+}
+
 // UpdateFiletype updates the filetype option of the Options struct
 func (o Options) UpdateFiletype(filetype bool) Options {
 	o.Filetype = filetype
@@ -98,13 +242,32 @@ func (o Options) UpdateCacheSize(size int64) Options {
 	return o
 }
 
+// UpdateTailSize updates how many bytes are kept from the end of the stream, with
+// Filetype on theyre used to detect the file type of the trailer (i.e. an appended zip)
+func (o Options) UpdateTailSize(size int64) Options {
+	o.TailSize = size
+	return o
+}
+
+// UpdateAnalyzers updates the analyzers of the Options struct
+func (o Options) UpdateAnalyzers(names ...string) Options {
+	o.Analyzers = append([]string{}, names...)
+	return o
+}
+
+// AddAnalyzer adds an analyzer to the Options struct
+func (o Options) AddAnalyzer(name string) Options {
+	o.Analyzers = append(append([]string{}, o.Analyzers...), name)
+	return o
+}
+
 // NewWriter creates a new Writer with the given options
 func (o Options) NewWriter(w ...io.Writer) *Writer {
 	return newWriterWithOptions(o, w...)
 }
 
 func (o Options) minCachSize() int64 {
-	if !o.Filetype {
+	if !o.Filetype && o.OnFiletype == nil {
 		return o.CacheSize
 	}
 	ftCachSize := int64(filetype.MaxBytesFileDetect())
@@ -0,0 +1,127 @@
+package identifiers
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	parallelBuffers   = 8
+	parallelChunkSize = 128 * 1024
+)
+
+// chunk is a buffer shared by the parallel workers, it is reused once every worker has written it
+type chunk struct {
+	data []byte
+	refs atomic.Int32
+}
+
+// newChunks creates the buffers used by the parallel writers, they are reused across resets
+func newChunks() chan *chunk {
+	free := make(chan *chunk, parallelBuffers)
+	for i := 0; i < parallelBuffers; i++ {
+		free <- &chunk{data: make([]byte, 0, parallelChunkSize)}
+	}
+	return free
+}
+
+// parallelWriter writes each chunk to every writer in its own goroutine so the
+// hashes run on different cores. Data is copied into a fixed number of reused
+// buffers, Write blocks when they are all in use and Close waits for every writer.
+// The goroutines are started by the first Write so an unused writer doesnt leak them
+type parallelWriter struct {
+	writers []io.Writer
+	workers []chan *chunk
+	free    chan *chunk
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	err     error
+}
+
+func newParallelWriter(free chan *chunk, w ...io.Writer) *parallelWriter {
+	return &parallelWriter{free: free, writers: w}
+}
+
+// start starts a goroutine for each writer
+func (pw *parallelWriter) start() {
+	for _, writer := range pw.writers {
+		ch := make(chan *chunk, parallelBuffers)
+		pw.workers = append(pw.workers, ch)
+		pw.wg.Add(1)
+		go pw.work(writer, ch)
+	}
+}
+
+func (pw *parallelWriter) work(w io.Writer, ch chan *chunk) {
+	defer pw.wg.Done()
+	for c := range ch {
+		if _, err := w.Write(c.data); err != nil {
+			pw.setError(err)
+		}
+		if c.refs.Add(-1) == 0 {
+			pw.free <- c
+		}
+	}
+}
+
+func (pw *parallelWriter) setError(err error) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if pw.err == nil {
+		pw.err = err
+	}
+}
+
+func (pw *parallelWriter) error() error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	return pw.err
+}
+
+// Write queues p for every writer, an error from a writer is returned by the next Write or Close
+func (pw *parallelWriter) Write(p []byte) (int, error) {
+	if len(pw.writers) == 0 || len(p) == 0 {
+		return len(p), nil
+	}
+	if pw.workers == nil {
+		pw.start()
+	}
+	written := 0
+	for len(p) > 0 {
+		if err := pw.error(); err != nil {
+			return written, err
+		}
+		c := <-pw.free
+		n := min(len(p), cap(c.data))
+		c.data = append(c.data[:0], p[:n]...)
+		c.refs.Store(int32(len(pw.workers)))
+		for _, ch := range pw.workers {
+			ch <- c
+		}
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close waits for every writer to finish and stops the goroutines, if any were started
+func (pw *parallelWriter) Close() error {
+	for _, ch := range pw.workers {
+		close(ch)
+	}
+	pw.wg.Wait()
+	return pw.err
+}
+
+// wait blocks until every writer has caught up and returns any error from them
+func (pw *parallelWriter) wait() error {
+	chunks := make([]*chunk, 0, parallelBuffers)
+	for i := 0; i < parallelBuffers; i++ {
+		chunks = append(chunks, <-pw.free)
+	}
+	for _, c := range chunks {
+		pw.free <- c
+	}
+	return pw.error()
+}
@@ -0,0 +1,131 @@
+package identifiers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+var errFailing = errors.New("failing analyzer")
+
+// failingAnalyzer is an analyzer that always fails
+type failingAnalyzer struct{}
+
+func (failingAnalyzer) Write(p []byte) (int, error) { return 0, errFailing }
+func (failingAnalyzer) Reset()                      {}
+func (failingAnalyzer) Result() any                 { return nil }
+
+func init() {
+	RegisterAnalyzer("test-failing", func() Analyzer { return failingAnalyzer{} })
+}
+
+func allOptions() Options {
+	return NewDefultOptions().
+		UpdateSha224(true).
+		UpdateSha384(true).
+		UpdateSha512_256(true).
+		UpdateCrc32(true).
+		UpdateCrc64(true).
+		UpdateAdler32(true).
+		UpdateFnv32a(true).
+		UpdateFnv64a(true).
+		UpdateHmacSha256(true).
+		UpdateHmacKey("test", []byte("secret")).
+		UpdateSsdeep(true).
+		UpdateTlsh(true).
+		UpdateEntropyStats(true).
+		UpdateClassify(true).
+		AddAnalyzer("test-lines")
+}
+
+func identify(t *testing.T, w *Writer, data []byte) Identifiers {
+	t.Helper()
+	w.Reset()
+	// odd sized writes so chunks dont line up with the buffers
+	if _, err := io.CopyBuffer(w, bytes.NewReader(data), make([]byte, 100003)); err != nil {
+		t.Fatalf("failed to copy data %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close writer %v", err)
+	}
+	i, err := w.Identifiers()
+	if err != nil {
+		t.Fatalf("failed to get identifiers %v", err)
+	}
+	return i
+}
+
+func TestConcurrentWriter(t *testing.T) {
+	data := make([]byte, 2*parallelChunkSize*parallelBuffers+7)
+	rand.New(rand.NewSource(1)).Read(data)
+	exp := identify(t, allOptions().NewWriter(), data)
+
+	t.Run("same as sequential", func(t *testing.T) {
+		w := allOptions().UpdateConcurrent(true).NewWriter()
+		for i := 0; i < 2; i++ {
+			if act := identify(t, w, data); !reflect.DeepEqual(act, exp) {
+				t.Errorf("expected %+v, got %+v", exp, act)
+			}
+		}
+		if act := identify(t, w, data[:10]); act.Size != 10 {
+			t.Errorf("expected size 10 after reset, got %v", act.Size)
+		}
+	})
+
+	t.Run("extra writers", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := allOptions().UpdateConcurrent(true).NewWriter()
+		w.Reset(&buf)
+		w.Write(data)
+		w.Close()
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("expected extra writer to get all the data")
+		}
+	})
+
+	t.Run("nothing turned on", func(t *testing.T) {
+		w := Options{Concurrent: true}.NewWriter()
+		if act := identify(t, w, data); act.Size != int64(len(data)) {
+			t.Errorf("expected size %d, got %v", len(data), act.Size)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		w := NewChecksumOptions().AddAnalyzer("test-failing").UpdateConcurrent(true).NewWriter()
+		w.Write(data)
+		if err := w.Close(); !errors.Is(err, errFailing) {
+			t.Errorf("expected failing error from close, got %v", err)
+		}
+		if _, err := w.Identifiers(); !errors.Is(err, errFailing) {
+			t.Errorf("expected failing error from identifiers, got %v", err)
+		}
+		// write fails once the error is seen
+		w.Reset()
+		var err error
+		for i := 0; i < 100 && err == nil; i++ {
+			_, err = w.Write(data[:parallelChunkSize])
+		}
+		if !errors.Is(err, errFailing) {
+			t.Errorf("expected failing error from write, got %v", err)
+		}
+		w.Close()
+	})
+	t.Run("not written", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+		for i := 0; i < 10; i++ {
+			w := NewDefultOptions().UpdateConcurrent(true).NewWriter()
+			w.Reset()
+			if _, err := w.MarshalBinary(); err != nil {
+				t.Fatalf("expected nil error for checkpoint, got %v", err)
+			}
+		}
+		// writers that are never written to dont start goroutines so they dont need to be closed
+		if after := runtime.NumGoroutine(); after > before {
+			t.Errorf("expected at most %d goroutines, got %d", before, after)
+		}
+	})
+}
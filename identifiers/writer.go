@@ -6,57 +6,192 @@ import (
 	"crypto/sha256"
 	"crypto/sha512"
 	"hash"
+	"hash/adler32"
+	"hash/crc32"
+	"hash/crc64"
+	"hash/fnv"
 	"io"
 	"os"
+	"time"
 
 	"github.com/jonathongardner/fifo/cache"
 	"github.com/jonathongardner/fifo/entropy"
+	"github.com/jonathongardner/fifo/filetype"
+	"github.com/jonathongardner/fifo/ssdeep"
+	"github.com/jonathongardner/fifo/tlsh"
 )
 
-// Writer is a writer that calculates the md5, sha1, sha256, sha512 hashes
-// and the entropy of the data written to it. It also detects the file type
+// Writer is a writer that calculates the md5, sha1, sha256, sha512 (and other
+// checksums turned on in the options) hashes and the entropy of the data written
+// to it. It also detects the file type and runs any analyzers turned on in the options
 type Writer struct {
-	md5     hash.Hash
-	sha1    hash.Hash
-	sha256  hash.Hash
-	sha512  hash.Hash
-	entropy *entropy.Writer
-	cache   *cache.Writer
-	ftype   bool
-	mw      io.Writer
-	closed  bool
+	md5              hash.Hash
+	sha1             hash.Hash
+	sha256           hash.Hash
+	sha512           hash.Hash
+	sha224           hash.Hash
+	sha384           hash.Hash
+	sha512_256       hash.Hash
+	crc32            hash.Hash
+	crc64            hash.Hash
+	adler32          hash.Hash
+	fnv32a           hash.Hash
+	fnv64a           hash.Hash
+	hmacSha256       hash.Hash
+	hmacSha512       hash.Hash
+	hmacKeyID        string
+	ssdeep           *ssdeep.Writer
+	tlsh             *tlsh.Writer
+	entropy          *entropy.Writer
+	analyzers        map[string]Analyzer
+	cache            *cache.Writer
+	tail             *cache.TailWriter // end of the stream for the trailer file type, nil if off
+	entStats         bool
+	classify         bool
+	ftype            bool
+	onFiletype       func(filetype.Filetype) error
+	filetypeDone     bool // onFiletype has been called
+	progress         ProgressFunc
+	progressInterval time.Duration
+	chunks           chan *chunk     // buffers for the parallel writer, nil if not concurrent
+	parallel         *parallelWriter // running parallel writer, nil if not concurrent or closed
+	mw               io.Writer
+	closed           bool
+	err              error // from the options, returned until the writer is recreated
+	writeErr         error // from the parallel writers, cleared on reset
 }
 
 // NewMultiWriterWithOptions creates a new Writer that writes to the given io.Writer
 // and calculates info based on options
 func newWriterWithOptions(o Options, w ...io.Writer) *Writer {
-	toReturn := &Writer{}
-	if o.Md5 {
-		toReturn.md5 = md5.New()
-		w = append(w, toReturn.md5)
+	toReturn := &Writer{
+		md5:        newHash(o.Md5, md5.New),
+		sha1:       newHash(o.Sha1, sha1.New),
+		sha256:     newHash(o.Sha256, sha256.New),
+		sha512:     newHash(o.Sha512, sha512.New),
+		sha224:     newHash(o.Sha224, sha256.New224),
+		sha384:     newHash(o.Sha384, sha512.New384),
+		sha512_256: newHash(o.Sha512_256, sha512.New512_256),
+		crc32:      newHash(o.Crc32, func() hash.Hash { return crc32.NewIEEE() }),
+		crc64:      newHash(o.Crc64, func() hash.Hash { return crc64.New(crc64.MakeTable(crc64.ECMA)) }),
+		adler32:    newHash(o.Adler32, func() hash.Hash { return adler32.New() }),
+		fnv32a:     newHash(o.Fnv32a, func() hash.Hash { return fnv.New32a() }),
+		fnv64a:     newHash(o.Fnv64a, func() hash.Hash { return fnv.New64a() }),
 	}
-	if o.Sha1 {
-		toReturn.sha1 = sha1.New()
-		w = append(w, toReturn.sha1)
+	var err error
+	toReturn.hmacSha256, toReturn.hmacSha512, err = newHmacs(o)
+	if err != nil {
+		// returned on write so the constructor doesnt need to return an error
+		toReturn.err = err
+	} else if toReturn.hmacSha256 != nil || toReturn.hmacSha512 != nil {
+		toReturn.hmacKeyID = o.HmacKeyID
 	}
-	if o.Sha256 {
-		toReturn.sha256 = sha256.New()
-		w = append(w, toReturn.sha256)
+	if o.Ssdeep {
+		toReturn.ssdeep = ssdeep.NewWriter()
 	}
-	if o.Sha512 {
-		toReturn.sha512 = sha512.New()
-		w = append(w, toReturn.sha512)
+	if o.Tlsh {
+		toReturn.tlsh = tlsh.NewWriter()
 	}
-	if o.Entropy {
+	if o.EntropyStats {
+		toReturn.entropy = entropy.NewStatsWriter()
+	} else if o.Entropy || o.Classify {
 		toReturn.entropy = entropy.NewWriter()
-		w = append(w, toReturn.entropy)
 	}
+	for _, name := range o.Analyzers {
+		a, err := newAnalyzer(name)
+		if err != nil {
+			// returned on write so the constructor doesnt need to return an error
+			toReturn.err = err
+			continue
+		}
+		if toReturn.analyzers == nil {
+			toReturn.analyzers = make(map[string]Analyzer)
+		}
+		toReturn.analyzers[name] = a
+	}
+	toReturn.entStats = o.EntropyStats
+	toReturn.classify = o.Classify
 	toReturn.ftype = o.Filetype
+	toReturn.onFiletype = o.OnFiletype
+	toReturn.progress = o.Progress
+	toReturn.progressInterval = o.ProgressInterval
+	if o.Concurrent {
+		toReturn.chunks = newChunks()
+	}
 	// Always set cached cause its used to calculate the size
 	toReturn.cache = cache.NewWriter(o.minCachSize())
-	w = append(w, toReturn.cache)
+	if o.TailSize > 0 && (o.Filetype || o.OnFiletype != nil) {
+		toReturn.tail = cache.NewTailWriter(o.TailSize)
+	}
+
+	toReturn.setWriters(w)
+	return toReturn
+}
+
+// setWriters sets the writers data is written to, w are the extra writers from the caller
+// they (and the cache) are written in the calling goroutine even when concurrent
+func (mw *Writer) setWriters(w []io.Writer) {
+	internal := make([]io.Writer, 0)
+	for _, h := range mw.hashes() {
+		internal = append(internal, h)
+	}
+	if mw.ssdeep != nil {
+		internal = append(internal, mw.ssdeep)
+	}
+	if mw.tlsh != nil {
+		internal = append(internal, mw.tlsh)
+	}
+	if mw.entropy != nil {
+		internal = append(internal, mw.entropy)
+	}
+	for _, a := range mw.analyzers {
+		internal = append(internal, a)
+	}
+
+	w = append(w, mw.cache)
+	if mw.tail != nil {
+		w = append(w, mw.tail)
+	}
+	if mw.chunks != nil {
+		mw.parallel = newParallelWriter(mw.chunks, internal...)
+		w = append(w, mw.parallel)
+	} else {
+		w = append(w, internal...)
+	}
+	mw.mw = io.MultiWriter(w...)
+}
 
-	toReturn.mw = io.MultiWriter(w...)
+// newHash returns a new hash if its turned on, otherwise nil
+func newHash(on bool, new func() hash.Hash) hash.Hash {
+	if !on {
+		return nil
+	}
+	return new()
+}
+
+// hashes returns the hashes that are turned on
+func (mw *Writer) hashes() []hash.Hash {
+	toReturn := make([]hash.Hash, 0)
+	for _, h := range mw.namedHashes() {
+		toReturn = append(toReturn, h)
+	}
+	return toReturn
+}
+
+// namedHashes returns the hashes that are turned on by their json name
+func (mw *Writer) namedHashes() map[string]hash.Hash {
+	toReturn := make(map[string]hash.Hash)
+	all := map[string]hash.Hash{
+		"md5": mw.md5, "sha1": mw.sha1, "sha256": mw.sha256, "sha512": mw.sha512,
+		"sha224": mw.sha224, "sha384": mw.sha384, "sha512_256": mw.sha512_256,
+		"crc32": mw.crc32, "crc64": mw.crc64, "adler32": mw.adler32, "fnv32a": mw.fnv32a, "fnv64a": mw.fnv64a,
+		"hmac_sha256": mw.hmacSha256, "hmac_sha512": mw.hmacSha512,
+	}
+	for name, h := range all {
+		if h != nil {
+			toReturn[name] = h
+		}
+	}
 	return toReturn
 }
 
@@ -68,7 +203,67 @@ func (mw *Writer) Write(p []byte) (int, error) {
 	if mw.closed {
 		return 0, os.ErrClosed
 	}
-	return mw.mw.Write(p)
+	if mw.err != nil {
+		return 0, mw.err
+	}
+	if mw.writeErr != nil {
+		return 0, mw.writeErr
+	}
+	n, err := mw.mw.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, mw.filetypeCallback()
+}
+
+// filetypeReady returns true once enough data has been written to detect the file type
+func (mw *Writer) filetypeReady() bool {
+	return mw.closed || mw.cache.Size() >= int64(filetype.MaxBytesFileDetect())
+}
+
+// filetypeCallback calls onFiletype once the file type is ready, an error from
+// it is kept and returned from Write, Close and Identifiers until reset
+func (mw *Writer) filetypeCallback() error {
+	if mw.onFiletype == nil || mw.filetypeDone || !mw.filetypeReady() {
+		return nil
+	}
+	mw.filetypeDone = true
+	if err := mw.onFiletype(mw.detectFiletype()); err != nil {
+		mw.writeErr = err
+		return err
+	}
+	return nil
+}
+
+// Filetype returns the file type as soon as its known, which is once
+// filetype.MaxBytesFileDetect bytes have been written or the writer is closed.
+// It returns false before then or if file type detection is off
+func (mw *Writer) Filetype() (filetype.Filetype, bool) {
+	if (!mw.ftype && mw.onFiletype == nil) || !mw.filetypeReady() {
+		return filetype.Filetype{}, false
+	}
+	return mw.detectFiletype(), true
+}
+
+// detectFiletype returns the file type from the cache, and once closed the tail
+func (mw *Writer) detectFiletype() filetype.Filetype {
+	if mw.closed && mw.tail != nil {
+		return filetype.NewFiletypeFromHeadTail(mw.cache.Bytes(), mw.tail.Bytes())
+	}
+	return filetype.NewFiletypeFromCached(mw.cache)
+}
+
+// trailerFiletype returns the file type of the end of the stream, nil if the tail
+// is off, nothing is recognized or the file type already is one (a docx is a zip)
+func (mw *Writer) trailerFiletype(ft filetype.Filetype) *filetype.Filetype {
+	if mw.tail == nil {
+		return nil
+	}
+	trailer, ok := filetype.NewTrailerFiletype(mw.tail.Bytes())
+	if !ok || ft.Is(trailer.Mimetype) {
+		return nil
+	}
+	return &trailer
 }
 
 func (iw *Writer) AddWriter(w io.Writer) {
@@ -80,39 +275,59 @@ func (mw *Writer) Cache() *cache.Writer {
 	return mw.cache
 }
 
+// Close finishes the writer, when concurrent it waits for the hashes to catch up
+// (so it must be called to stop the goroutines) and returns any error from them
 func (mw *Writer) Close() error {
 	if mw.closed {
 		return os.ErrClosed
 	}
 	mw.closed = true
 
-	return nil
+	ftErr := mw.filetypeCallback()
+	if err := mw.stopParallel(); err != nil {
+		return err
+	}
+	return ftErr
+}
+
+// stopParallel waits for the parallel writer to finish
+func (mw *Writer) stopParallel() error {
+	if mw.parallel == nil {
+		return nil
+	}
+	err := mw.parallel.Close()
+	mw.parallel = nil
+	if err != nil {
+		mw.writeErr = err
+	}
+	return err
 }
 
 func (mw *Writer) Reset(w ...io.Writer) {
+	// finish any data still being hashed before resetting
+	mw.stopParallel()
 	mw.closed = false
-	if mw.md5 != nil {
-		mw.md5.Reset()
-		w = append(w, mw.md5)
+	mw.writeErr = nil
+	mw.filetypeDone = false
+	for _, h := range mw.hashes() {
+		h.Reset()
 	}
-	if mw.sha1 != nil {
-		mw.sha1.Reset()
-		w = append(w, mw.sha1)
+	if mw.ssdeep != nil {
+		mw.ssdeep.Reset()
 	}
-	if mw.sha256 != nil {
-		mw.sha256.Reset()
-		w = append(w, mw.sha256)
-	}
-	if mw.sha512 != nil {
-		mw.sha512.Reset()
-		w = append(w, mw.sha512)
+	if mw.tlsh != nil {
+		mw.tlsh.Reset()
 	}
 	if mw.entropy != nil {
 		mw.entropy.Reset()
-		w = append(w, mw.entropy)
+	}
+	for _, a := range mw.analyzers {
+		a.Reset()
 	}
 
 	mw.cache.Reset()
-	w = append(w, mw.cache)
-	mw.mw = io.MultiWriter(w...)
+	if mw.tail != nil {
+		mw.tail.Reset()
+	}
+	mw.setWriters(w)
 }
@@ -3,10 +3,16 @@ package identifiers
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/json"
 	"io"
+	"math/rand"
+	"reflect"
 	"testing"
 
+	"github.com/jonathongardner/fifo/entropy"
 	"github.com/jonathongardner/fifo/filetype"
+	"github.com/jonathongardner/fifo/ssdeep"
+	"github.com/jonathongardner/fifo/tlsh"
 )
 
 func gzipCompress(data []byte) ([]byte, error) {
@@ -202,3 +208,168 @@ func TestAddWriter(t *testing.T) {
 	}
 	assertIdentifiers(t, exp, i)
 }
+
+func TestChecksumWriter(t *testing.T) {
+	toWrite := []byte("Something cool")
+
+	o := NewOptions(false, false, false, false, false, false, 0).
+		UpdateSha224(true).
+		UpdateSha384(true).
+		UpdateSha512_256(true).
+		UpdateCrc32(true).
+		UpdateCrc64(true).
+		UpdateAdler32(true).
+		UpdateFnv32a(true).
+		UpdateFnv64a(true)
+	w := o.NewWriter()
+
+	exp := Identifiers{
+		Sha224:     "cbcafe2f4615f4acf6c61347d6308fadff907957b1cc18c7a36b270b",
+		Sha384:     "7379ffec00a0f5bcc8c16166de9356109edb48cb6f6445711b9369946acf624a8de74a9add040d41767fba700288f147",
+		Sha512_256: "fecd415601fb7eeba6198acd2d3dbfc3c7b4fbc7f5eb023274d0df962197d782",
+		Crc32:      "2051db0d",
+		Crc64:      "c59bdbb61246104a",
+		Adler32:    "2955057c",
+		Fnv32a:     "854d70b0",
+		Fnv64a:     "dddeb90f84135c70",
+		Size:       14,
+	}
+
+	// run twice to make sure reset works
+	for i := 0; i < 2; i++ {
+		w.Reset()
+		if _, err := io.Copy(w, bytes.NewReader(toWrite)); err != nil {
+			t.Fatalf("failed to copy data %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("failed to close writer %v", err)
+		}
+		act, err := w.Identifiers()
+		if err != nil {
+			t.Fatalf("failed to get identifiers %v", err)
+		}
+		if !reflect.DeepEqual(act, exp) {
+			t.Errorf("checksum mismatch, expected %+v, got %+v", exp, act)
+		}
+	}
+
+	t.Run("json keys", func(t *testing.T) {
+		data, err := json.Marshal(exp)
+		if err != nil {
+			t.Fatalf("failed to marshal identifiers %v", err)
+		}
+		for _, key := range []string{"sha224", "sha384", "sha512_256", "crc32", "crc64", "adler32", "fnv32a", "fnv64a"} {
+			if !bytes.Contains(data, []byte(`"`+key+`":`)) {
+				t.Errorf("expected %s key in %s", key, string(data))
+			}
+		}
+	})
+}
+
+func TestSsdeepWriter(t *testing.T) {
+	toWrite := []byte("Something cool")
+
+	w := NewChecksumOptions().UpdateSsdeep(true).NewWriter()
+	for i := 0; i < 2; i++ {
+		w.Reset()
+		if _, err := io.Copy(w, bytes.NewReader(toWrite)); err != nil {
+			t.Fatalf("failed to copy data %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("failed to close writer %v", err)
+		}
+		act, err := w.Identifiers()
+		if err != nil {
+			t.Fatalf("failed to get identifiers %v", err)
+		}
+		if exp := ssdeep.Hash(toWrite); act.Ssdeep != exp {
+			t.Errorf("ssdeep mismatch, expected %v, got %v", exp, act.Ssdeep)
+		}
+	}
+
+	w = NewChecksumOptions().NewWriter()
+	w.Write(toWrite)
+	w.Close()
+	if act, _ := w.Identifiers(); act.Ssdeep != "" {
+		t.Errorf("expected no ssdeep, got %v", act.Ssdeep)
+	}
+}
+
+func TestTlshWriter(t *testing.T) {
+	toWrite := make([]byte, 1000)
+	rand.New(rand.NewSource(1)).Read(toWrite)
+
+	w := NewChecksumOptions().UpdateTlsh(true).NewWriter()
+	for i := 0; i < 2; i++ {
+		w.Reset()
+		if _, err := io.Copy(w, bytes.NewReader(toWrite)); err != nil {
+			t.Fatalf("failed to copy data %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("failed to close writer %v", err)
+		}
+		act, err := w.Identifiers()
+		if err != nil {
+			t.Fatalf("failed to get identifiers %v", err)
+		}
+		if exp, _ := tlsh.Hash(toWrite); act.Tlsh != exp {
+			t.Errorf("tlsh mismatch, expected %v, got %v", exp, act.Tlsh)
+		}
+	}
+
+	w.Reset()
+	w.Write([]byte("Something cool"))
+	w.Close()
+	if act, _ := w.Identifiers(); act.Tlsh != "not enough data" {
+		t.Errorf("expected not enough data, got %v", act.Tlsh)
+	}
+}
+
+func TestEntropyStatsWriter(t *testing.T) {
+	toWrite := []byte("Something cool")
+
+	w := NewChecksumOptions().UpdateEntropyStats(true).NewWriter()
+	w.Write(toWrite)
+	w.Close()
+	act, err := w.Identifiers()
+	if err != nil {
+		t.Fatalf("failed to get identifiers %v", err)
+	}
+	ent := entropy.NewStatsWriter()
+	ent.Write(toWrite)
+	if act.EntropyStats == nil || *act.EntropyStats != ent.Stats() {
+		t.Errorf("stats mismatch, expected %+v, got %+v", ent.Stats(), act.EntropyStats)
+	}
+	if act.Entropy != ent.Entropy() {
+		t.Errorf("entropy mismatch, expected %v, got %v", ent.Entropy(), act.Entropy)
+	}
+
+	w = NewDefultOptions().NewWriter()
+	w.Write(toWrite)
+	w.Close()
+	if act, _ := w.Identifiers(); act.EntropyStats != nil {
+		t.Errorf("expected no stats, got %+v", act.EntropyStats)
+	}
+}
+
+func TestClassifyWriter(t *testing.T) {
+	toWrite := []byte("Something cool")
+
+	w := NewChecksumOptions().UpdateClassify(true).NewWriter()
+	w.Write(toWrite)
+	w.Close()
+	act, err := w.Identifiers()
+	if err != nil {
+		t.Fatalf("failed to get identifiers %v", err)
+	}
+	if act.Class == nil || act.Class.Class != entropy.Plaintext {
+		t.Errorf("expected plaintext, got %+v", act.Class)
+	}
+
+	w = NewDefultOptions().NewWriter()
+	w.Write(toWrite)
+	w.Close()
+	if act, _ := w.Identifiers(); act.Class != nil {
+		t.Errorf("expected no classification, got %+v", act.Class)
+	}
+}
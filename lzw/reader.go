@@ -0,0 +1,50 @@
+package lzw
+
+import (
+	"compress/lzw"
+	"io"
+
+	"github.com/jonathongardner/fifo"
+)
+
+var _ fifo.ReadCloseReseter = (*Reader)(nil)
+
+// Reader is a lzw reader that can be reset back to the start of the stream
+type Reader struct {
+	lr       *lzw.Reader
+	r        io.ReadSeeker
+	order    lzw.Order
+	litWidth int
+}
+
+// NewReader Creates a new lzw reader, see compress/lzw for order and litWidth
+// r must be a ReadSeeker, needed to seek to reset the reader
+func NewReader(r io.ReadSeeker, order lzw.Order, litWidth int) *Reader {
+	lr := lzw.NewReader(r, order, litWidth).(*lzw.Reader)
+	return &Reader{lr: lr, r: r, order: order, litWidth: litWidth}
+}
+
+// Read reads data from the lzw reader
+func (r *Reader) Read(p []byte) (int, error) {
+	return r.lr.Read(p)
+}
+
+// Close closes the lzw reader and the underlying reader if it implements io.Closer
+func (r *Reader) Close() error {
+	if err := r.lr.Close(); err != nil {
+		return err
+	}
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Reset resets the lzw reader to the beginning of the stream
+func (r *Reader) Reset() error {
+	if _, err := r.r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r.lr.Reset(r.r, r.order, r.litWidth)
+	return nil
+}
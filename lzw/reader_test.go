@@ -0,0 +1,65 @@
+package lzw
+
+import (
+	"bytes"
+	"compress/lzw"
+	"io"
+	"testing"
+)
+
+func lzwCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := lzw.NewWriter(&buf, lzw.LSB, 8)
+	_, err := writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func TestLzwReader(t *testing.T) {
+	toWrite := []byte("Your String Here")
+	compressed, err := lzwCompress(toWrite)
+	if err != nil {
+		t.Fatalf("failed to compress data %v", err)
+	}
+	r := bytes.NewReader(compressed)
+	reader := NewReader(r, lzw.LSB, 8)
+
+	v1, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal("failed to read lzw", err)
+	}
+	if string(v1) != string(toWrite) {
+		t.Fatalf("expected %s but got %s", string(toWrite), string(v1))
+	}
+
+	v2 := make([]byte, len(toWrite))
+	n2, err := reader.Read(v2)
+	if err != io.EOF {
+		t.Fatal("expected EOF", err)
+	}
+	if n2 != 0 {
+		t.Fatalf("expected 0 bytes but got %d", n2)
+	}
+
+	if err := reader.Reset(); err != nil {
+		t.Fatal("failed to reset lzw", err)
+	}
+
+	v3, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal("failed to read lzw after reset", err)
+	}
+	if string(v3) != string(toWrite) {
+		t.Fatalf("expected %s but got %s after reset", string(toWrite), string(v3))
+	}
+
+	if err := reader.Close(); err != nil {
+		t.Fatal("failed to close lzw", err)
+	}
+}
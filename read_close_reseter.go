@@ -1,7 +1,93 @@
 package fifo
 
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// ReadCloseReseter is a reader that can be closed and reset to its initial
+// state so the same stream can be read again
 type ReadCloseReseter interface {
 	Close() error
-	Read(byte) (int, error)
+	Read([]byte) (int, error)
 	Reset() error
 }
+
+type readSeekReseter struct {
+	r io.ReadSeeker
+}
+
+// NewReadSeekReseter creates a ReadCloseReseter from a ReadSeeker
+// Reset seeks back to the start, Close closes r if it implements io.Closer
+func NewReadSeekReseter(r io.ReadSeeker) ReadCloseReseter {
+	return &readSeekReseter{r: r}
+}
+
+// NewFileReseter creates a ReadCloseReseter from an open file
+// Reset seeks back to the start, Close closes the file
+func NewFileReseter(f *os.File) ReadCloseReseter {
+	return &readSeekReseter{r: f}
+}
+
+// NewBytesReseter creates a ReadCloseReseter that reads the given data
+func NewBytesReseter(data []byte) ReadCloseReseter {
+	return &readSeekReseter{r: bytes.NewReader(data)}
+}
+
+func (r *readSeekReseter) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func (r *readSeekReseter) Close() error {
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (r *readSeekReseter) Reset() error {
+	_, err := r.r.Seek(0, io.SeekStart)
+	return err
+}
+
+type pathReseter struct {
+	path string
+	file *os.File
+}
+
+// OpenReseter opens the file at the given path as a ReadCloseReseter
+// Reset closes and re-opens the path, so it also works for files that can't seek
+func OpenReseter(path string) (ReadCloseReseter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &pathReseter{path: path, file: file}, nil
+}
+
+func (r *pathReseter) Read(p []byte) (int, error) {
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	return r.file.Read(p)
+}
+
+func (r *pathReseter) Close() error {
+	if r.file == nil {
+		return os.ErrClosed
+	}
+	defer func() { r.file = nil }()
+	return r.file.Close()
+}
+
+func (r *pathReseter) Reset() error {
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			return err
+		}
+	}
+	var err error
+	r.file, err = os.Open(r.path)
+	return err
+}
@@ -0,0 +1,88 @@
+package fifo
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func assertReadAll(t *testing.T, r ReadCloseReseter, exp string) {
+	t.Helper()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("expected nil error for read, got %v", err)
+	}
+	if string(data) != exp {
+		t.Errorf("expected %s, got %s", exp, string(data))
+	}
+}
+
+func assertReseter(t *testing.T, r ReadCloseReseter, exp string) {
+	t.Helper()
+	assertReadAll(t, r, exp)
+	if err := r.Reset(); err != nil {
+		t.Fatalf("expected nil error for reset, got %v", err)
+	}
+	assertReadAll(t, r, exp)
+	if err := r.Close(); err != nil {
+		t.Fatalf("expected nil error for close, got %v", err)
+	}
+}
+
+func TestReseters(t *testing.T) {
+	toWrite := []byte("Something cool")
+
+	tmpDir, err := os.MkdirTemp("", "tmp")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, "foo")
+	if err := os.WriteFile(path, toWrite, 0644); err != nil {
+		t.Fatalf("failed to write file %v", err)
+	}
+
+	t.Run("bytes", func(t *testing.T) {
+		assertReseter(t, NewBytesReseter(toWrite), string(toWrite))
+	})
+
+	t.Run("file", func(t *testing.T) {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("failed to open file %v", err)
+		}
+		assertReseter(t, NewFileReseter(f), string(toWrite))
+
+		// make sure the file was closed
+		if _, err := f.Read(make([]byte, 1)); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("expected closed error for read from file, got %v", err)
+		}
+	})
+
+	t.Run("path", func(t *testing.T) {
+		r, err := OpenReseter(path)
+		if err != nil {
+			t.Fatalf("failed to open reseter %v", err)
+		}
+		assertReseter(t, r, string(toWrite))
+
+		if _, err := r.Read(make([]byte, 1)); err != os.ErrClosed {
+			t.Errorf("expected closed error for read after close, got %v", err)
+		}
+		// reset re-opens the file
+		if err := r.Reset(); err != nil {
+			t.Fatalf("expected nil error for reset after close, got %v", err)
+		}
+		assertReadAll(t, r, string(toWrite))
+		r.Close()
+	})
+
+	t.Run("path does not exist", func(t *testing.T) {
+		_, err := OpenReseter(filepath.Join(tmpDir, "bar"))
+		if !os.IsNotExist(err) {
+			t.Errorf("expected not exist error, got %v", err)
+		}
+	})
+}
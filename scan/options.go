@@ -0,0 +1,51 @@
+package scan
+
+import (
+	"context"
+	"io/fs"
+	"runtime"
+
+	"github.com/jonathongardner/fifo/identifiers"
+)
+
+// Options is a struct that contains options for scanning
+type Options struct {
+	Identifiers identifiers.Options
+	Workers     int // number of files identified at the same time
+}
+
+// NewDefultOptions creates a new Options struct with default values
+// default identifiers and a worker per cpu
+func NewDefultOptions() Options {
+	return NewOptions(identifiers.NewDefultOptions(), runtime.NumCPU())
+}
+
+// NewOptions creates a new Options struct with the given options
+func NewOptions(o identifiers.Options, workers int) Options {
+	return Options{
+		Identifiers: o,
+		Workers:     workers,
+	}
+}
+
+// UpdateIdentifiers updates the identifiers options of the Options struct
+func (o Options) UpdateIdentifiers(i identifiers.Options) Options {
+	o.Identifiers = i
+	return o
+}
+
+// UpdateWorkers updates the number of workers of the Options struct
+func (o Options) UpdateWorkers(workers int) Options {
+	o.Workers = workers
+	return o
+}
+
+// Dir identifies every file under the directory, see FS
+func (o Options) Dir(ctx context.Context, root string) <-chan Result {
+	return dirWithOptions(ctx, o, root)
+}
+
+// FS identifies every file under root in fsys, see FS
+func (o Options) FS(ctx context.Context, fsys fs.FS, root string) <-chan Result {
+	return fsWithOptions(ctx, o, fsys, root, "")
+}
@@ -0,0 +1,148 @@
+package scan
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/jonathongardner/fifo/filetype"
+	"github.com/jonathongardner/fifo/identifiers"
+)
+
+// Result is the identifiers of a file found while scanning
+// Info is from lstat so links are not followed, Err is set if the file couldnt be identified
+type Result struct {
+	Path        string
+	Identifiers identifiers.Identifiers
+	Info        fs.FileInfo
+	Err         error
+}
+
+// Dir identifies every file under the directory using the default options, see FS
+func Dir(ctx context.Context, root string) <-chan Result {
+	return dirWithOptions(ctx, NewDefultOptions(), root)
+}
+
+// FS identifies every file under root in fsys using the default options
+// Files are identified by a pool of workers and the results are sent on the
+// returned channel, which is closed once everything is done or ctx is cancelled.
+// Directories and symlinks are reported as filetype.Dir and filetype.Symlink without being read
+func FS(ctx context.Context, fsys fs.FS, root string) <-chan Result {
+	return fsWithOptions(ctx, NewDefultOptions(), fsys, root, "")
+}
+
+func dirWithOptions(ctx context.Context, o Options, root string) <-chan Result {
+	return fsWithOptions(ctx, o, os.DirFS(root), ".", root)
+}
+
+// fsWithOptions walks fsys, prefix is joined to the paths in the results
+func fsWithOptions(ctx context.Context, o Options, fsys fs.FS, root string, prefix string) <-chan Result {
+	workers := o.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	results := make(chan Result, workers)
+	jobs := make(chan job, workers)
+
+	send := func(r Result) bool {
+		select {
+		case results <- r:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := o.Identifiers.NewWriter()
+			for j := range jobs {
+				if !send(j.identify(ctx, w, fsys)) {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer func() {
+			close(jobs)
+			wg.Wait()
+			close(results)
+		}()
+
+		fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			r := Result{Path: path}
+			if prefix != "" {
+				r.Path = filepath.Join(prefix, filepath.FromSlash(path))
+			}
+			if err != nil {
+				r.Err = err
+				if !send(r) {
+					return ctx.Err()
+				}
+				// skip the directory that couldnt be read but keep walking
+				if d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+
+			r.Info, r.Err = d.Info()
+			switch {
+			case r.Err != nil:
+				// lstat failed, send the error
+			case d.IsDir():
+				r.Identifiers = identifiers.Identifiers{Filetype: filetype.Dir}
+			case d.Type()&fs.ModeSymlink != 0:
+				r.Identifiers = identifiers.Identifiers{Filetype: filetype.Symlink}
+			case d.Type().IsRegular():
+				select {
+				case jobs <- job{path: path, result: r}:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			// devices, pipes etc are sent without identifiers
+			if !send(r) {
+				return ctx.Err()
+			}
+			return nil
+		})
+	}()
+
+	return results
+}
+
+// job is a file to identify, path is the path in the fs.FS
+type job struct {
+	path   string
+	result Result
+}
+
+// identify calculates the identifiers of the file, reusing the writer
+func (j job) identify(ctx context.Context, w *identifiers.Writer, fsys fs.FS) Result {
+	r := j.result
+	f, err := fsys.Open(j.path)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	defer f.Close()
+
+	w.Reset()
+	r.Identifiers, r.Err = w.Identify(ctx, f)
+	if r.Err == nil {
+		r.Identifiers.CheckFilename(j.path)
+	}
+	return r
+}
@@ -0,0 +1,118 @@
+package scan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jonathongardner/fifo/filetype"
+)
+
+func collect(ch <-chan Result) map[string]Result {
+	toReturn := make(map[string]Result)
+	for r := range ch {
+		toReturn[r.Path] = r
+	}
+	return toReturn
+}
+
+func assertResult(t *testing.T, results map[string]Result, path, mime, md5 string) {
+	t.Helper()
+	r, ok := results[path]
+	if !ok {
+		t.Fatalf("expected result for %s", path)
+	}
+	if r.Err != nil {
+		t.Errorf("expected nil error for %s, got %v", path, r.Err)
+	}
+	if r.Identifiers.Filetype.Mimetype != mime {
+		t.Errorf("expected %s mimetype for %s, got %s", mime, path, r.Identifiers.Filetype.Mimetype)
+	}
+	if r.Identifiers.Md5 != md5 {
+		t.Errorf("expected %s md5 for %s, got %s", md5, path, r.Identifiers.Md5)
+	}
+	if r.Info == nil {
+		t.Errorf("expected info for %s", path)
+	}
+}
+
+func TestDir(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "tmp")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	fooMd5 := "db5ee56e2cab72f4e46bdd60965bef31"
+	if err := os.Mkdir(filepath.Join(tmpDir, "dir"), 0755); err != nil {
+		t.Fatalf("failed to create dir %v", err)
+	}
+	for _, name := range []string{"foo", "dir/bar", "dir/baz"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("Something cool"), 0644); err != nil {
+			t.Fatalf("failed to write file %v", err)
+		}
+	}
+	if err := os.Symlink("foo", filepath.Join(tmpDir, "link")); err != nil {
+		t.Fatalf("failed to create link %v", err)
+	}
+
+	t.Run("identifies", func(t *testing.T) {
+		results := collect(NewDefultOptions().UpdateWorkers(2).Dir(context.Background(), tmpDir))
+		if len(results) != 6 {
+			t.Fatalf("expected 6 results, got %d", len(results))
+		}
+		assertResult(t, results, tmpDir, filetype.Dir.Mimetype, "")
+		assertResult(t, results, filepath.Join(tmpDir, "dir"), filetype.Dir.Mimetype, "")
+		assertResult(t, results, filepath.Join(tmpDir, "link"), filetype.Symlink.Mimetype, "")
+		for _, name := range []string{"foo", "dir/bar", "dir/baz"} {
+			assertResult(t, results, filepath.Join(tmpDir, name), "text/plain; charset=utf-8", fooMd5)
+		}
+		if results[filepath.Join(tmpDir, "link")].Info.Mode()&os.ModeSymlink == 0 {
+			t.Errorf("expected lstat info for link")
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results := collect(Dir(ctx, tmpDir))
+		if len(results) != 0 {
+			t.Errorf("expected no results after cancel, got %d", len(results))
+		}
+	})
+
+	t.Run("does not exist", func(t *testing.T) {
+		results := collect(Dir(context.Background(), filepath.Join(tmpDir, "nope")))
+		if len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		if r := results[filepath.Join(tmpDir, "nope")]; !os.IsNotExist(r.Err) {
+			t.Errorf("expected not exist error, got %v", r.Err)
+		}
+	})
+}
+
+func TestFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"foo":     {Data: []byte("Something cool")},
+		"dir/bar": {Data: []byte("Something cool")},
+		"a.txt":   {Data: []byte("Something cool")},
+		"a.png":   {Data: []byte("Something cool")},
+	}
+	results := collect(FS(context.Background(), fsys, "."))
+	if len(results) != 6 {
+		t.Fatalf("expected 6 results, got %d", len(results))
+	}
+	assertResult(t, results, ".", filetype.Dir.Mimetype, "")
+	assertResult(t, results, "dir", filetype.Dir.Mimetype, "")
+	assertResult(t, results, "foo", "text/plain; charset=utf-8", "db5ee56e2cab72f4e46bdd60965bef31")
+	assertResult(t, results, "dir/bar", "text/plain; charset=utf-8", "db5ee56e2cab72f4e46bdd60965bef31")
+
+	for path, expected := range map[string]bool{"foo": false, "a.txt": false, "a.png": true, "dir": false} {
+		if actual := results[path].Identifiers.Mismatch; actual != expected {
+			t.Errorf("expected %v extension mismatch for %s, got %v", expected, path, actual)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package ssdeep
+
+import (
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidHash = fmt.Errorf("invalid ssdeep hash")
+
+// Compare returns how similar two ssdeep hashes are, 0 is not similar and 100 is a (near) match
+func Compare(hash1, hash2 string) (int, error) {
+	bs1, s11, s12, err := parse(hash1)
+	if err != nil {
+		return 0, err
+	}
+	bs2, s21, s22, err := parse(hash2)
+	if err != nil {
+		return 0, err
+	}
+
+	// only hashes with the same or neighbour block sizes can be compared
+	if bs1 != bs2 && bs1 != bs2*2 && bs2 != bs1*2 {
+		return 0, nil
+	}
+
+	s11, s12 = eliminateSequences(s11), eliminateSequences(s12)
+	s21, s22 = eliminateSequences(s21), eliminateSequences(s22)
+
+	if bs1 == bs2 && s11 == s21 && s12 == s22 {
+		return 100, nil
+	}
+
+	switch {
+	case bs1 == bs2:
+		return max(scoreStrings(s11, s21, bs1), scoreStrings(s12, s22, bs1*2)), nil
+	case bs1 == bs2*2:
+		return scoreStrings(s11, s22, bs1), nil
+	default:
+		return scoreStrings(s12, s21, bs2), nil
+	}
+}
+
+// eliminateSequences shortens runs of the same character to 3, they carry little information
+func eliminateSequences(s string) string {
+	if len(s) <= 3 {
+		return s
+	}
+	var sb strings.Builder
+	sb.WriteString(s[:3])
+	for i := 3; i < len(s); i++ {
+		if s[i] != s[i-1] || s[i] != s[i-2] || s[i] != s[i-3] {
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// hasCommonSubstring checks if the strings share a substring as long as the rolling window
+func hasCommonSubstring(s1, s2 string) bool {
+	if len(s1) < rollingWindow || len(s2) < rollingWindow {
+		return false
+	}
+	for i := 0; i+rollingWindow <= len(s1); i++ {
+		if strings.Contains(s2, s1[i:i+rollingWindow]) {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance is the levenshtein distance where a substitution costs 2
+func editDistance(s1, s2 string) int {
+	prev := make([]int, len(s2)+1)
+	cur := make([]int, len(s2)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s1); i++ {
+		cur[0] = i
+		for j := 1; j <= len(s2); j++ {
+			cost := prev[j-1]
+			if s1[i-1] != s2[j-1] {
+				cost += 2
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(s2)]
+}
+
+func scoreStrings(s1, s2 string, blockSize uint64) int {
+	if len(s1) > spamsumLength || len(s2) > spamsumLength {
+		return 0
+	}
+	if !hasCommonSubstring(s1, s2) {
+		return 0
+	}
+
+	score := uint64(editDistance(s1, s2))
+	score = (score * spamsumLength) / uint64(len(s1)+len(s2))
+	score = (100 * score) / spamsumLength
+	if score >= 100 {
+		return 0
+	}
+	score = 100 - score
+
+	// dont exaggerate the match size for small block sizes
+	if blockSize >= (99+rollingWindow)/rollingWindow*minBlockSize {
+		return int(score)
+	}
+	if limit := blockSize / minBlockSize * uint64(min(len(s1), len(s2))); score > limit {
+		score = limit
+	}
+	return int(score)
+}
@@ -0,0 +1,78 @@
+package ssdeep
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+var ErrInvalidState = fmt.Errorf("invalid ssdeep state")
+
+const (
+	stateMagic    = "ssd"
+	stateVersion  = 2
+	blockStateLen = 4*3 + spamsumLength + 1
+	stateLen      = len(stateMagic) + 1 + numBlockHashes*blockStateLen + 2 + rollingWindow*4 + 4*4 + 8
+)
+
+// MarshalBinary saves the state of the writer
+func (w *Writer) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, stateLen)
+	b = append(b, stateMagic...)
+	b = append(b, stateVersion)
+	for _, bh := range w.bh {
+		b = binary.BigEndian.AppendUint32(b, bh.h)
+		b = binary.BigEndian.AppendUint32(b, bh.halfh)
+		b = binary.BigEndian.AppendUint32(b, uint32(bh.dindex))
+		b = append(b, bh.digest[:]...)
+		b = append(b, bh.halfdigest)
+	}
+	b = append(b, byte(w.bhstart), byte(w.bhend))
+	for _, c := range w.roll.window {
+		b = binary.BigEndian.AppendUint32(b, c)
+	}
+	for _, v := range []uint32{w.roll.h1, w.roll.h2, w.roll.h3, w.roll.n} {
+		b = binary.BigEndian.AppendUint32(b, v)
+	}
+	b = binary.BigEndian.AppendUint64(b, w.totalSize)
+	return b, nil
+}
+
+// UnmarshalBinary restores the state of the writer saved with MarshalBinary
+func (w *Writer) UnmarshalBinary(b []byte) error {
+	if len(b) != stateLen || string(b[:len(stateMagic)]) != stateMagic || b[len(stateMagic)] != stateVersion {
+		return ErrInvalidState
+	}
+	b = b[len(stateMagic)+1:]
+	next := func() uint32 {
+		v := binary.BigEndian.Uint32(b)
+		b = b[4:]
+		return v
+	}
+
+	s := Writer{}
+	for i := range s.bh {
+		s.bh[i].h = next()
+		s.bh[i].halfh = next()
+		s.bh[i].dindex = int(next())
+		copy(s.bh[i].digest[:], b)
+		b = b[spamsumLength:]
+		s.bh[i].halfdigest = b[0]
+		b = b[1:]
+		if s.bh[i].dindex >= spamsumLength {
+			return ErrInvalidState
+		}
+	}
+	s.bhstart, s.bhend = int(b[0]), int(b[1])
+	b = b[2:]
+	for i := range s.roll.window {
+		s.roll.window[i] = next()
+	}
+	s.roll.h1, s.roll.h2, s.roll.h3, s.roll.n = next(), next(), next(), next()
+	s.totalSize = binary.BigEndian.Uint64(b)
+
+	if s.bhstart >= s.bhend || s.bhend > numBlockHashes || s.roll.n >= rollingWindow {
+		return ErrInvalidState
+	}
+	*w = s
+	return nil
+}
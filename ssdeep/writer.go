@@ -0,0 +1,235 @@
+package ssdeep
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Context triggered piecewise hashing, compatible with ssdeep (libfuzzy)
+// https://ssdeep-project.github.io/ssdeep/
+
+const (
+	rollingWindow  = 7
+	minBlockSize   = 3
+	hashPrime      = 0x01000193
+	hashInit       = 0x27
+	spamsumLength  = 64
+	numBlockHashes = 31
+	b64            = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+)
+
+func blockSize(i int) uint64 {
+	return uint64(minBlockSize) << i
+}
+
+// rolling is the rolling hash used to find the trigger points
+type rolling struct {
+	window     [rollingWindow]uint32
+	h1, h2, h3 uint32
+	n          uint32
+}
+
+func (r *rolling) hash(c byte) {
+	r.h2 -= r.h1
+	r.h2 += rollingWindow * uint32(c)
+	r.h1 += uint32(c)
+	r.h1 -= r.window[r.n]
+	r.window[r.n] = uint32(c)
+	r.n = (r.n + 1) % rollingWindow
+	r.h3 <<= 5
+	r.h3 ^= uint32(c)
+}
+
+func (r *rolling) sum() uint32 {
+	return r.h1 + r.h2 + r.h3
+}
+
+// blockHash is the digest for one block size
+// digest[dindex] is only set once the digest is full, its the piece with the rest
+// of the data. halfdigest is the last piece of the first half of the digest
+type blockHash struct {
+	h          uint32
+	halfh      uint32
+	digest     [spamsumLength]byte
+	halfdigest byte
+	dindex     int
+}
+
+func sumHash(c byte, h uint32) uint32 {
+	return (h * hashPrime) ^ uint32(c)
+}
+
+// Writer is an io.Writer like object that calculates the ssdeep fuzzy hash of the data written
+type Writer struct {
+	bh        [numBlockHashes]blockHash
+	bhstart   int
+	bhend     int
+	roll      rolling
+	totalSize uint64
+}
+
+// NewWriter creates a new ssdeep writer
+func NewWriter() *Writer {
+	w := &Writer{}
+	w.Reset()
+	return w
+}
+
+// Write adds data to the hash, it never returns an error
+func (w *Writer) Write(p []byte) (int, error) {
+	w.totalSize += uint64(len(p))
+	for _, c := range p {
+		w.step(c)
+	}
+	return len(p), nil
+}
+
+// Reset resets the writer so it can be used for a new stream
+func (w *Writer) Reset() {
+	*w = Writer{bhend: 1}
+	w.bh[0].h = hashInit
+	w.bh[0].halfh = hashInit
+}
+
+// Size returns the number of bytes written
+func (w *Writer) Size() uint64 {
+	return w.totalSize
+}
+
+func (w *Writer) forkBlockHash() {
+	if w.bhend >= numBlockHashes {
+		return
+	}
+	obh := &w.bh[w.bhend-1]
+	nbh := &w.bh[w.bhend]
+	nbh.h = obh.h
+	nbh.halfh = obh.halfh
+	nbh.digest[0] = 0
+	nbh.halfdigest = 0
+	nbh.dindex = 0
+	w.bhend++
+}
+
+// reduceBlockHash drops the smallest block size once it can't be picked for the digest
+func (w *Writer) reduceBlockHash() {
+	if w.bhend-w.bhstart < 2 {
+		return
+	}
+	if blockSize(w.bhstart)*spamsumLength >= w.totalSize {
+		return
+	}
+	if w.bh[w.bhstart+1].dindex < spamsumLength/2 {
+		return
+	}
+	w.bhstart++
+}
+
+func (w *Writer) step(c byte) {
+	w.roll.hash(c)
+	h := uint64(w.roll.sum())
+
+	for i := w.bhstart; i < w.bhend; i++ {
+		w.bh[i].h = sumHash(c, w.bh[i].h)
+		w.bh[i].halfh = sumHash(c, w.bh[i].halfh)
+	}
+
+	for i := w.bhstart; i < w.bhend; i++ {
+		// once this fails for one block size it fails for all the bigger ones
+		if h%blockSize(i) != blockSize(i)-1 {
+			break
+		}
+		bh := &w.bh[i]
+		if bh.dindex == 0 {
+			// first trigger for this block size, start the next one
+			w.forkBlockHash()
+		}
+		bh.digest[bh.dindex] = b64[bh.h%64]
+		bh.halfdigest = b64[bh.halfh%64]
+		if bh.dindex < spamsumLength-1 {
+			// the last piece takes the rest of the data once the digest is full
+			bh.dindex++
+			bh.digest[bh.dindex] = 0
+			bh.h = hashInit
+			if bh.dindex < spamsumLength/2 {
+				bh.halfh = hashInit
+				bh.halfdigest = 0
+			}
+		} else {
+			w.reduceBlockHash()
+		}
+	}
+}
+
+// Sum returns the fuzzy hash in the ssdeep format blocksize:hash:hash2
+func (w *Writer) Sum() string {
+	bi := w.bhstart
+	h := w.roll.sum()
+
+	// initial block size guess from the size
+	for blockSize(bi)*spamsumLength < w.totalSize {
+		bi++
+		if bi >= numBlockHashes {
+			// more data than ssdeep supports (~ 192GB), use the biggest block size
+			bi = numBlockHashes - 1
+			break
+		}
+	}
+	// adapt the guess to the actual digest length
+	for bi >= w.bhend {
+		bi--
+	}
+	for bi > w.bhstart && w.bh[bi].dindex < spamsumLength/2 {
+		bi--
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strconv.FormatUint(blockSize(bi), 10))
+	sb.WriteByte(':')
+	sb.Write(w.bh[bi].digest[:w.bh[bi].dindex])
+	if h != 0 {
+		sb.WriteByte(b64[w.bh[bi].h%64])
+	} else if c := w.bh[bi].digest[w.bh[bi].dindex]; c != 0 {
+		// the digest is full and ended on a trigger
+		sb.WriteByte(c)
+	}
+	sb.WriteByte(':')
+	if bi < w.bhend-1 {
+		bh := &w.bh[bi+1]
+		i := bh.dindex
+		if i > spamsumLength/2-1 {
+			i = spamsumLength/2 - 1
+		}
+		sb.Write(bh.digest[:i])
+		if h != 0 {
+			sb.WriteByte(b64[bh.halfh%64])
+		} else if bh.halfdigest != 0 {
+			sb.WriteByte(bh.halfdigest)
+		}
+	} else if h != 0 {
+		sb.WriteByte(b64[w.bh[bi].h%64])
+	}
+	return sb.String()
+}
+
+// Hash returns the ssdeep fuzzy hash of data
+func Hash(data []byte) string {
+	w := NewWriter()
+	w.Write(data)
+	return w.Sum()
+}
+
+// parse splits a hash into its block size and two digests
+func parse(hash string) (uint64, string, string, error) {
+	parts := strings.SplitN(hash, ":", 3)
+	if len(parts) != 3 {
+		return 0, "", "", fmt.Errorf("%w: %s", ErrInvalidHash, hash)
+	}
+	bs, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("%w: %s", ErrInvalidHash, hash)
+	}
+	// ignore the file name if the hash is from the ssdeep output
+	s2, _, _ := strings.Cut(parts[2], ",")
+	return bs, parts[1], s2, nil
+}
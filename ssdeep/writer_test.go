@@ -0,0 +1,212 @@
+package ssdeep
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func randomData(seed int64, size int) []byte {
+	r := rand.New(rand.NewSource(seed))
+	data := make([]byte, size)
+	r.Read(data)
+	return data
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		if h := Hash([]byte{}); h != "3::" {
+			t.Errorf("expected 3::, got %s", h)
+		}
+	})
+
+	t.Run("small", func(t *testing.T) {
+		if h := Hash([]byte("a")); h != "3:E:E" {
+			t.Errorf("expected 3:E:E, got %s", h)
+		}
+	})
+
+	t.Run("zero rolling hash", func(t *testing.T) {
+		// data ending in 7 zero bytes leaves the rolling hash at 0 so the digests end with the
+		// last piece (when full) and the half digest instead of the current hash, expected
+		// values are from a C transliteration of libfuzzy's fuzzy_engine_step and fuzzy_digest
+		tests := []struct {
+			seed int64
+			size int
+			exp  string
+		}{
+			{3, 611, "12:lh5oWugUIkMJqy1hY28lZ3ujcI6wf2lIzLrrNUK85ItlP2x3IGSqaI20JsBJo:lh5oWumk0Z1b8lZ3aOg2MiWfi3IGSqLt"},
+			{5, 885, "12:akCGQXMFd9u0pS5uOuT/pLa3dXN5lrMbYDEE5Xuo2VljcS8fdsI5q9TCuFVOnl9F:akCGQXaFpea+3JtQxG+9bjcT/uOga"},
+		}
+		for _, tt := range tests {
+			data := append(randomData(tt.seed, tt.size), make([]byte, 7+int(tt.seed%5))...)
+			if h := Hash(data); h != tt.exp {
+				t.Errorf("expected %s, got %s", tt.exp, h)
+			}
+		}
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		data := randomData(1, 100000)
+		exp := Hash(data)
+
+		w := NewWriter()
+		for i := 0; i < len(data); i += 7 {
+			w.Write(data[i:min(i+7, len(data))])
+		}
+		if w.Sum() != exp {
+			t.Errorf("expected %s, got %s", exp, w.Sum())
+		}
+
+		w.Reset()
+		w.Write(data)
+		if w.Sum() != exp {
+			t.Errorf("expected %s after reset, got %s", exp, w.Sum())
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
+		h := Hash(randomData(2, 100000))
+		bs, s1, s2, err := parse(h)
+		if err != nil {
+			t.Fatalf("expected nil error for parse, got %v", err)
+		}
+		// block size is picked so the digest is between 32 and 64 characters
+		if bs*spamsumLength < 100000/2 || bs > 100000/spamsumLength*2 {
+			t.Errorf("unexpected block size %d", bs)
+		}
+		if len(s1) < spamsumLength/2 || len(s1) > spamsumLength {
+			t.Errorf("unexpected digest length %d %s", len(s1), s1)
+		}
+		if len(s2) > spamsumLength/2 {
+			t.Errorf("unexpected second digest length %d %s", len(s2), s2)
+		}
+		if strings.ContainsAny(s1+s2, ":,") {
+			t.Errorf("unexpected characters in %s", h)
+		}
+	})
+}
+
+func TestCompare(t *testing.T) {
+	data := randomData(3, 50000)
+	h1 := Hash(data)
+
+	t.Run("same", func(t *testing.T) {
+		score, err := Compare(h1, h1)
+		if err != nil {
+			t.Fatalf("expected nil error for compare, got %v", err)
+		}
+		if score != 100 {
+			t.Errorf("expected 100, got %d", score)
+		}
+	})
+
+	t.Run("similar", func(t *testing.T) {
+		changed := bytes.Clone(data)
+		copy(changed[20000:], []byte("Something cool in the middle"))
+		score, err := Compare(h1, Hash(changed))
+		if err != nil {
+			t.Fatalf("expected nil error for compare, got %v", err)
+		}
+		if score < 80 || score == 100 {
+			t.Errorf("expected a high score for similar data, got %d", score)
+		}
+	})
+
+	t.Run("appended", func(t *testing.T) {
+		score, err := Compare(h1, Hash(append(bytes.Clone(data), randomData(4, 5000)...)))
+		if err != nil {
+			t.Fatalf("expected nil error for compare, got %v", err)
+		}
+		if score < 50 {
+			t.Errorf("expected a high score for appended data, got %d", score)
+		}
+	})
+
+	t.Run("different", func(t *testing.T) {
+		score, err := Compare(h1, Hash(randomData(5, 50000)))
+		if err != nil {
+			t.Fatalf("expected nil error for compare, got %v", err)
+		}
+		if score != 0 {
+			t.Errorf("expected 0 for different data, got %d", score)
+		}
+	})
+
+	t.Run("block sizes", func(t *testing.T) {
+		score, err := Compare("3:abcdefgh:abcd", "48:abcdefgh:abcd")
+		if err != nil {
+			t.Fatalf("expected nil error for compare, got %v", err)
+		}
+		if score != 0 {
+			t.Errorf("expected 0 for block sizes that dont match, got %d", score)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := Compare(h1, "nope"); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("expected invalid hash error, got %v", err)
+		}
+		if _, err := Compare("x:abc:abc", h1); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("expected invalid hash error, got %v", err)
+		}
+	})
+}
+
+func TestEliminateSequences(t *testing.T) {
+	tests := map[string]string{
+		"":          "",
+		"aaa":       "aaa",
+		"aaaaaa":    "aaa",
+		"abbbbbbc":  "abbbc",
+		"aaaabaaaa": "aaabaaa",
+	}
+	for in, exp := range tests {
+		if act := eliminateSequences(in); act != exp {
+			t.Errorf("expected %s for %s, got %s", exp, in, act)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		s1, s2 string
+		exp    int
+	}{
+		{"", "", 0},
+		{"abc", "abc", 0},
+		{"abc", "abd", 2},
+		{"abc", "ab", 1},
+		{"", "abc", 3},
+	}
+	for _, test := range tests {
+		if act := editDistance(test.s1, test.s2); act != test.exp {
+			t.Errorf("expected %d for %s %s, got %d", test.exp, test.s1, test.s2, act)
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	data := randomData(3, 100000)
+	exp := Hash(data)
+
+	w := NewWriter()
+	w.Write(data[:40000])
+	state, err := w.MarshalBinary()
+	if err != nil {
+		t.Fatalf("expected nil error for marshal, got %v", err)
+	}
+	restored := NewWriter()
+	if err := restored.UnmarshalBinary(state); err != nil {
+		t.Fatalf("expected nil error for unmarshal, got %v", err)
+	}
+	restored.Write(data[40000:])
+	if restored.Sum() != exp {
+		t.Errorf("expected %s, got %s", exp, restored.Sum())
+	}
+	if err := restored.UnmarshalBinary(state[1:]); err != ErrInvalidState {
+		t.Errorf("expected invalid state error, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package tlsh
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidHash = fmt.Errorf("invalid tlsh hash")
+
+const (
+	version   = "T1"
+	digestLen = 3 + codeSize
+)
+
+// Digest is a parsed TLSH digest
+type Digest struct {
+	Checksum byte
+	Lvalue   byte
+	Q1Ratio  byte
+	Q2Ratio  byte
+	Code     [codeSize]byte
+}
+
+func swapByte(b byte) byte {
+	return b<<4 | b>>4
+}
+
+// String returns the digest in the T1 hex format
+func (d Digest) String() string {
+	b := make([]byte, 0, digestLen)
+	b = append(b, swapByte(d.Checksum), swapByte(d.Lvalue), d.Q1Ratio<<4|d.Q2Ratio)
+	for i := codeSize - 1; i >= 0; i-- {
+		b = append(b, d.Code[i])
+	}
+	return version + strings.ToUpper(hex.EncodeToString(b))
+}
+
+// ParseDigest parses a hex digest, with or without the T1 version prefix
+func ParseDigest(hash string) (Digest, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(hash, version))
+	if err != nil || len(b) != digestLen {
+		return Digest{}, fmt.Errorf("%w: %s", ErrInvalidHash, hash)
+	}
+	d := Digest{
+		Checksum: swapByte(b[0]),
+		Lvalue:   swapByte(b[1]),
+		Q1Ratio:  b[2] >> 4,
+		Q2Ratio:  b[2] & 0x0F,
+	}
+	for i := range d.Code {
+		d.Code[i] = b[digestLen-1-i]
+	}
+	return d, nil
+}
+
+// modDiff is the distance between x and y going either way around r
+func modDiff(x, y, r int) int {
+	dl, dr := x-y, y+r-x
+	if y > x {
+		dl, dr = y-x, x+r-y
+	}
+	return min(dl, dr)
+}
+
+// Distance returns the distance between two digests, 0 is a (near) match and
+// the bigger the value the less similar, around 100 or less is usually related
+func (d Digest) Distance(other Digest) int {
+	diff := 0
+	if ldiff := modDiff(int(d.Lvalue), int(other.Lvalue), 256); ldiff <= 1 {
+		diff += ldiff
+	} else {
+		diff += ldiff * 12
+	}
+	for _, qdiff := range []int{
+		modDiff(int(d.Q1Ratio), int(other.Q1Ratio), 16),
+		modDiff(int(d.Q2Ratio), int(other.Q2Ratio), 16),
+	} {
+		if qdiff <= 1 {
+			diff += qdiff
+		} else {
+			diff += (qdiff - 1) * 12
+		}
+	}
+	if d.Checksum != other.Checksum {
+		diff++
+	}
+	for i := range d.Code {
+		x, y := d.Code[i], other.Code[i]
+		for j := 0; j < 4; j++ {
+			bd := int(x>>(2*j)&3) - int(y>>(2*j)&3)
+			switch {
+			case bd == 3 || bd == -3:
+				diff += 6
+			case bd < 0:
+				diff -= bd
+			default:
+				diff += bd
+			}
+		}
+	}
+	return diff
+}
+
+// Distance returns the distance between two hex digests, see Digest.Distance
+func Distance(hash1, hash2 string) (int, error) {
+	d1, err := ParseDigest(hash1)
+	if err != nil {
+		return 0, err
+	}
+	d2, err := ParseDigest(hash2)
+	if err != nil {
+		return 0, err
+	}
+	return d1.Distance(d2), nil
+}
@@ -0,0 +1,48 @@
+package tlsh
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+var ErrInvalidState = fmt.Errorf("invalid tlsh state")
+
+const (
+	stateMagic   = "tlsh"
+	stateVersion = 1
+	stateLen     = len(stateMagic) + 1 + windowSize + buckets*4 + 1 + 8
+)
+
+// MarshalBinary saves the state of the writer
+func (w *Writer) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, stateLen)
+	b = append(b, stateMagic...)
+	b = append(b, stateVersion)
+	b = append(b, w.window[:]...)
+	for _, c := range w.buckets {
+		b = binary.BigEndian.AppendUint32(b, c)
+	}
+	b = append(b, w.checksum)
+	b = binary.BigEndian.AppendUint64(b, w.size)
+	return b, nil
+}
+
+// UnmarshalBinary restores the state of the writer saved with MarshalBinary
+func (w *Writer) UnmarshalBinary(b []byte) error {
+	if len(b) != stateLen || string(b[:len(stateMagic)]) != stateMagic || b[len(stateMagic)] != stateVersion {
+		return ErrInvalidState
+	}
+	b = b[len(stateMagic)+1:]
+
+	s := Writer{}
+	copy(s.window[:], b)
+	b = b[windowSize:]
+	for i := range s.buckets {
+		s.buckets[i] = binary.BigEndian.Uint32(b)
+		b = b[4:]
+	}
+	s.checksum = b[0]
+	s.size = binary.BigEndian.Uint64(b[1:])
+	*w = s
+	return nil
+}
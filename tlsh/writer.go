@@ -0,0 +1,202 @@
+package tlsh
+
+import (
+	"errors"
+	"slices"
+)
+
+// Trend Micro locality sensitive hash (TLSH), compatible with the T1 digests from
+// https://github.com/trendmicro/tlsh using 128 buckets and a 1 byte checksum
+
+const (
+	windowSize = 5
+	buckets    = 256
+	effBuckets = 128
+	codeSize   = effBuckets / 4
+	// MinSize is the minimum number of bytes needed to calculate a digest
+	MinSize = 50
+	// MaxSize is the maximum number of bytes a digest can be calculated for
+	MaxSize = 1<<32 - 1
+)
+
+var (
+	ErrNotEnoughData     = errors.New("not enough data")
+	ErrNotEnoughVariance = errors.New("not enough variance")
+	ErrTooMuchData       = errors.New("too much data")
+)
+
+// pearson permutation table used for the bucket mapping
+var vTable = [256]byte{
+	1, 87, 49, 12, 176, 178, 102, 166, 121, 193, 6, 84, 249, 230, 44, 163,
+	14, 197, 213, 181, 161, 85, 218, 80, 64, 239, 24, 226, 236, 142, 38, 200,
+	110, 177, 104, 103, 141, 253, 255, 50, 77, 101, 81, 18, 45, 96, 31, 222,
+	25, 107, 190, 70, 86, 237, 240, 34, 72, 242, 20, 214, 244, 227, 149, 235,
+	97, 234, 57, 22, 60, 250, 82, 175, 208, 5, 127, 199, 111, 62, 135, 248,
+	174, 169, 211, 58, 66, 154, 106, 195, 245, 171, 17, 187, 182, 179, 0, 243,
+	132, 56, 148, 75, 128, 133, 158, 100, 130, 126, 91, 13, 153, 246, 216, 219,
+	119, 68, 223, 78, 83, 88, 201, 99, 122, 11, 92, 32, 136, 114, 52, 10,
+	138, 30, 48, 183, 156, 35, 61, 26, 143, 74, 251, 94, 129, 162, 63, 152,
+	170, 7, 115, 167, 241, 206, 3, 150, 55, 59, 151, 220, 90, 53, 23, 131,
+	125, 173, 15, 238, 79, 95, 89, 16, 105, 137, 225, 224, 217, 160, 37, 123,
+	118, 73, 2, 157, 46, 116, 9, 145, 134, 228, 207, 212, 202, 215, 69, 229,
+	27, 188, 67, 124, 168, 252, 42, 4, 29, 108, 21, 247, 19, 205, 39, 203,
+	233, 40, 186, 147, 198, 192, 155, 33, 164, 191, 98, 204, 165, 180, 117, 76,
+	140, 36, 210, 172, 41, 54, 159, 8, 185, 232, 113, 196, 231, 47, 146, 120,
+	51, 65, 28, 144, 254, 221, 93, 189, 194, 139, 112, 43, 71, 109, 184, 209,
+}
+
+func bMapping(salt, i, j, k byte) byte {
+	return vTable[vTable[vTable[vTable[salt]^i]^j]^k]
+}
+
+// Writer is an io.Writer like object that calculates the TLSH digest of the data written
+type Writer struct {
+	window   [windowSize]byte
+	buckets  [buckets]uint32
+	checksum byte
+	size     uint64
+}
+
+// NewWriter creates a new tlsh writer
+func NewWriter() *Writer {
+	return &Writer{}
+}
+
+// Write adds data to the hash, it never returns an error
+func (w *Writer) Write(p []byte) (int, error) {
+	for _, c := range p {
+		j := w.size % windowSize
+		w.window[j] = c
+		if w.size >= windowSize-1 {
+			// only calculate once there is a full window
+			c0 := c
+			c1 := w.window[(j+4)%windowSize]
+			c2 := w.window[(j+3)%windowSize]
+			c3 := w.window[(j+2)%windowSize]
+			c4 := w.window[(j+1)%windowSize]
+
+			w.checksum = bMapping(0, c0, c1, w.checksum)
+			w.buckets[bMapping(2, c0, c1, c2)]++
+			w.buckets[bMapping(3, c0, c1, c3)]++
+			w.buckets[bMapping(5, c0, c2, c3)]++
+			w.buckets[bMapping(7, c0, c2, c4)]++
+			w.buckets[bMapping(11, c0, c1, c4)]++
+			w.buckets[bMapping(13, c0, c3, c4)]++
+		}
+		w.size++
+	}
+	return len(p), nil
+}
+
+// Reset resets the writer so it can be used for a new stream
+func (w *Writer) Reset() {
+	*w = Writer{}
+}
+
+// Size returns the number of bytes written
+func (w *Writer) Size() uint64 {
+	return w.size
+}
+
+// Digest calculates the digest of the data written so far, an error is returned if
+// there is less than MinSize bytes, more than MaxSize or the data is too uniform
+func (w *Writer) Digest() (Digest, error) {
+	if w.size < MinSize {
+		return Digest{}, ErrNotEnoughData
+	}
+	if w.size > MaxSize {
+		return Digest{}, ErrTooMuchData
+	}
+
+	q1, q2, q3 := w.quartiles()
+	nonzero := 0
+	for _, b := range w.buckets[:effBuckets] {
+		if b > 0 {
+			nonzero++
+		}
+	}
+	// more than half the buckets have to be used
+	if q3 == 0 || nonzero <= effBuckets/2 {
+		return Digest{}, ErrNotEnoughVariance
+	}
+
+	d := Digest{
+		Checksum: w.checksum,
+		Lvalue:   lCapturing(w.size),
+		Q1Ratio:  byte(uint32(float32(q1*100)/float32(q3)) % 16),
+		Q2Ratio:  byte(uint32(float32(q2*100)/float32(q3)) % 16),
+	}
+	for i := range d.Code {
+		var h byte
+		for j := 0; j < 4; j++ {
+			switch k := w.buckets[4*i+j]; {
+			case q3 < k:
+				h += 3 << (j * 2)
+			case q2 < k:
+				h += 2 << (j * 2)
+			case q1 < k:
+				h += 1 << (j * 2)
+			}
+		}
+		d.Code[i] = h
+	}
+	return d, nil
+}
+
+// Sum returns the digest in the T1 hex format, or the error message if it
+// cant be calculated (i.e. "not enough data")
+func (w *Writer) Sum() string {
+	d, err := w.Digest()
+	if err != nil {
+		return err.Error()
+	}
+	return d.String()
+}
+
+func (w *Writer) quartiles() (uint32, uint32, uint32) {
+	sorted := w.buckets
+	s := sorted[:effBuckets]
+	slices.Sort(s)
+	return s[effBuckets/4-1], s[effBuckets/2-1], s[effBuckets-effBuckets/4-1]
+}
+
+// topval is the largest length for each l value, the reference implementation looks
+// the l value up in this table instead of calculating the log so it doesnt depend
+// on floating point rounding
+var topval = [...]uint32{
+	1, 2, 3, 5, 7, 11, 17, 25, 38, 57,
+	86, 129, 194, 291, 437, 656, 854, 1110, 1443, 1876,
+	2439, 3171, 3475, 3823, 4205, 4626, 5088, 5597, 6157, 6772,
+	7450, 8195, 9014, 9916, 10907, 11998, 13198, 14518, 15970, 17567,
+	19323, 21256, 23382, 25720, 28292, 31121, 34233, 37656, 41422, 45564,
+	50121, 55133, 60646, 66711, 73382, 80721, 88793, 97672, 107439, 118183,
+	130002, 143002, 157302, 173032, 190335, 209369, 230306, 253337, 278670, 306538,
+	337191, 370911, 408002, 448802, 493682, 543050, 597356, 657091, 722800, 795081,
+	874589, 962048, 1058252, 1164078, 1280486, 1408534, 1549388, 1704327, 1874759, 2062236,
+	2268459, 2495305, 2744836, 3019320, 3321252, 3653374, 4018711, 4420582, 4862641, 5348905,
+	5883796, 6472176, 7119394, 7831333, 8614467, 9475909, 10423501, 11465851, 12612437, 13873681,
+	15261050, 16787154, 18465870, 20312458, 22343706, 24578077, 27035886, 29739474, 32713425, 35984770,
+	39583245, 43541573, 47895730, 52685306, 57953837, 63749221, 70124148, 77136564, 84850228, 93335252,
+	102668779, 112935659, 124229227, 136652151, 150317384, 165349128, 181884040, 200072456, 220079703, 242087671,
+	266296456, 292926096, 322218735, 354440623, 389884688, 428873168, 471760495, 518936559, 570830240, 627913311,
+	690704607, 759775136, 835752671, 919327967, 1011260767, 1112386880, 1223623232, 1345985727, 1480584256, 1628642751,
+	1791507135, 1970657856, 2167723648, 2384496256, 2622945920, 2885240448, 3173764736, 3491141248, 3840255616, 4224281216,
+	4294967295,
+}
+
+// lCapturing is the log of the length, so files of similar size have similar values
+func lCapturing(size uint64) byte {
+	i, _ := slices.BinarySearch(topval[:], uint32(size))
+	return byte(i)
+}
+
+// Hash returns the TLSH digest of data in the T1 hex format
+func Hash(data []byte) (string, error) {
+	w := NewWriter()
+	w.Write(data)
+	d, err := w.Digest()
+	if err != nil {
+		return "", err
+	}
+	return d.String(), nil
+}
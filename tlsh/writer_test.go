@@ -0,0 +1,173 @@
+package tlsh
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func randomData(seed int64, size int) []byte {
+	r := rand.New(rand.NewSource(seed))
+	data := make([]byte, size)
+	r.Read(data)
+	return data
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("not enough data", func(t *testing.T) {
+		w := NewWriter()
+		w.Write(randomData(1, MinSize-1))
+		if _, err := w.Digest(); err != ErrNotEnoughData {
+			t.Errorf("expected not enough data error, got %v", err)
+		}
+		if s := w.Sum(); s != "not enough data" {
+			t.Errorf("expected not enough data, got %s", s)
+		}
+		if _, err := Hash(randomData(1, MinSize-1)); err != ErrNotEnoughData {
+			t.Errorf("expected not enough data error for hash, got %v", err)
+		}
+	})
+
+	t.Run("not enough variance", func(t *testing.T) {
+		if _, err := Hash(make([]byte, 1000)); err != ErrNotEnoughVariance {
+			t.Errorf("expected not enough variance error, got %v", err)
+		}
+	})
+
+	t.Run("min size", func(t *testing.T) {
+		w := NewWriter()
+		w.Write(randomData(1, MinSize))
+		if _, err := w.Digest(); err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+	})
+
+	t.Run("known digests", func(t *testing.T) {
+		// expected values are from a C transliteration of the reference trendmicro/tlsh
+		// update, final and hash, sizes are the minimum and where the l value changes
+		tests := []struct {
+			size int
+			exp  string
+		}{
+			{50, "T1A290020D55E8180C65E121C8048104950D122119492025618C127A6B05070D067D4381"},
+			{657, "T18E0123206ED2DE31D0A98839D5D5EFB9C22184EDB0F6A98D266C2826C14E865875DF88"},
+			{190336, "T1A01412C0BCA2C1683B0FDE0C65ED69A2D78A9C3AFF5DF8413503A35E71D611136AB949"},
+		}
+		for _, tt := range tests {
+			if h, err := Hash(randomData(int64(tt.size), tt.size)); err != nil || h != tt.exp {
+				t.Errorf("expected %s for %d bytes, got %s (%v)", tt.exp, tt.size, h, err)
+			}
+		}
+	})
+
+	t.Run("l value", func(t *testing.T) {
+		// the boundaries of the reference lookup table, a log of the size rounds differently on some
+		tests := map[uint64]byte{
+			50: 9, 656: 15, 657: 16, 3171: 21, 3172: 22, 3199: 22, 3200: 22,
+			190335: 64, 190336: 65, MaxSize: 170,
+		}
+		for size, exp := range tests {
+			if l := lCapturing(size); l != exp {
+				t.Errorf("expected l value %d for %d, got %d", exp, size, l)
+			}
+		}
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		data := randomData(1, 100000)
+		exp, err := Hash(data)
+		if err != nil {
+			t.Fatalf("expected nil error for hash, got %v", err)
+		}
+		if len(exp) != 72 || exp[:2] != "T1" {
+			t.Errorf("expected T1 digest with 72 characters, got %s", exp)
+		}
+
+		w := NewWriter()
+		for i := 0; i < len(data); i += 7 {
+			w.Write(data[i:min(i+7, len(data))])
+		}
+		if w.Sum() != exp {
+			t.Errorf("expected %s, got %s", exp, w.Sum())
+		}
+
+		w.Reset()
+		w.Write(data)
+		if w.Sum() != exp {
+			t.Errorf("expected %s after reset, got %s", exp, w.Sum())
+		}
+	})
+}
+
+func TestDistance(t *testing.T) {
+	data := randomData(2, 10000)
+	h1, _ := Hash(data)
+
+	t.Run("same", func(t *testing.T) {
+		if d, err := Distance(h1, h1); err != nil || d != 0 {
+			t.Errorf("expected 0, got %d (%v)", d, err)
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		d, err := ParseDigest(h1)
+		if err != nil {
+			t.Fatalf("expected nil error for parse, got %v", err)
+		}
+		if d.String() != h1 {
+			t.Errorf("expected %s, got %s", h1, d.String())
+		}
+		// version prefix is optional
+		if _, err := ParseDigest(h1[2:]); err != nil {
+			t.Errorf("expected nil error for parse without version, got %v", err)
+		}
+	})
+
+	t.Run("similar", func(t *testing.T) {
+		modified := append([]byte{}, data...)
+		copy(modified[5000:], randomData(3, 100))
+		h2, _ := Hash(modified)
+		h3, _ := Hash(randomData(4, 10000))
+
+		similar, _ := Distance(h1, h2)
+		different, _ := Distance(h1, h3)
+		if similar >= different {
+			t.Errorf("expected similar data to be closer, got %d and %d", similar, different)
+		}
+		if d, _ := Distance(h2, h1); d != similar {
+			t.Errorf("expected distance to be symmetric, got %d and %d", similar, d)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := Distance(h1, "T1ABC"); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("expected invalid hash error, got %v", err)
+		}
+		if _, err := Distance("not enough data", h1); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("expected invalid hash error, got %v", err)
+		}
+	})
+}
+
+func TestState(t *testing.T) {
+	data := randomData(3, 10000)
+	exp, _ := Hash(data)
+
+	w := NewWriter()
+	w.Write(data[:4000])
+	state, err := w.MarshalBinary()
+	if err != nil {
+		t.Fatalf("expected nil error for marshal, got %v", err)
+	}
+	restored := NewWriter()
+	if err := restored.UnmarshalBinary(state); err != nil {
+		t.Fatalf("expected nil error for unmarshal, got %v", err)
+	}
+	restored.Write(data[4000:])
+	if restored.Sum() != exp {
+		t.Errorf("expected %s, got %s", exp, restored.Sum())
+	}
+	if err := restored.UnmarshalBinary(state[1:]); err != ErrInvalidState {
+		t.Errorf("expected invalid state error, got %v", err)
+	}
+}
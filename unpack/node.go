@@ -0,0 +1,44 @@
+package unpack
+
+import (
+	"github.com/jonathongardner/fifo/identifiers"
+)
+
+// Node is a file found while unpacking, containers have the files
+// unpacked from them as children
+type Node struct {
+	Path        string                  `json:"path"`
+	Identifiers identifiers.Identifiers `json:"identifiers"`
+	Error       string                  `json:"error,omitempty"` // error unpacking the container
+	Parent      *Node                   `json:"-"`
+	Children    []*Node                 `json:"children,omitempty"`
+}
+
+func (n *Node) addChild(path string) *Node {
+	child := &Node{Path: path, Parent: n}
+	n.Children = append(n.Children, child)
+	return child
+}
+
+// Walk calls fn for the node and all of its children, depth first
+// stops and returns the error if fn returns one
+func (n *Node) Walk(fn func(*Node) error) error {
+	if err := fn(n); err != nil {
+		return err
+	}
+	for _, child := range n.Children {
+		if err := child.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Depth returns how many containers the node is in
+func (n *Node) Depth() int {
+	depth := 0
+	for p := n.Parent; p != nil; p = p.Parent {
+		depth++
+	}
+	return depth
+}
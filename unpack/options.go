@@ -0,0 +1,61 @@
+package unpack
+
+import (
+	"io"
+
+	"github.com/jonathongardner/fifo/identifiers"
+)
+
+// Options is a struct that contains options for unpacking
+type Options struct {
+	Identifiers identifiers.Options
+	MaxDepth    int    // how many levels of containers to unpack, 0 only identifies the stream
+	MemorySize  int    // max bytes of a container to keep in memory before using a temp file
+	TmpDir      string // directory for temp files, empty uses the default temp directory
+}
+
+// NewDefultOptions creates a new Options struct with default values
+// default identifiers, a max depth of 10 and 10MB kept in memory
+func NewDefultOptions() Options {
+	return NewOptions(identifiers.NewDefultOptions(), 10, 10*1024*1024, "")
+}
+
+// NewOptions creates a new Options struct with the given options
+func NewOptions(o identifiers.Options, maxDepth, memorySize int, tmpDir string) Options {
+	return Options{
+		Identifiers: o,
+		MaxDepth:    maxDepth,
+		MemorySize:  memorySize,
+		TmpDir:      tmpDir,
+	}
+}
+
+// UpdateIdentifiers updates the identifiers options of the Options struct
+// filetype is always calculated since its needed to find containers
+func (o Options) UpdateIdentifiers(i identifiers.Options) Options {
+	o.Identifiers = i
+	return o
+}
+
+// UpdateMaxDepth updates the max depth of the Options struct
+func (o Options) UpdateMaxDepth(maxDepth int) Options {
+	o.MaxDepth = maxDepth
+	return o
+}
+
+// UpdateMemorySize updates the memory size of the Options struct
+func (o Options) UpdateMemorySize(size int) Options {
+	o.MemorySize = size
+	return o
+}
+
+// UpdateTmpDir updates the temp directory of the Options struct
+func (o Options) UpdateTmpDir(dir string) Options {
+	o.TmpDir = dir
+	return o
+}
+
+// Unpack identifies the stream and recursively unpacks the containers in it
+func (o Options) Unpack(r io.Reader, path string) (*Node, error) {
+	return unpackWithOptions(o, r, path)
+}
@@ -0,0 +1,237 @@
+package unpack
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jonathongardner/fifo/ar"
+	"github.com/jonathongardner/fifo/buffer"
+	"github.com/jonathongardner/fifo/cpio"
+	"github.com/jonathongardner/fifo/decompress"
+	"github.com/jonathongardner/fifo/filetype"
+	"github.com/jonathongardner/fifo/identifiers"
+)
+
+// unpacker holds the state of a single Unpack call
+type unpacker struct {
+	options Options
+	tmpDir  string
+	count   int
+}
+
+// Unpack identifies the stream and recursively unpacks the containers in it
+// using the default options
+func Unpack(r io.Reader, path string) (*Node, error) {
+	return unpackWithOptions(NewDefultOptions(), r, path)
+}
+
+func unpackWithOptions(o Options, r io.Reader, path string) (*Node, error) {
+	if o.MemorySize <= 0 {
+		return nil, fmt.Errorf("memory size must be greater than 0")
+	}
+	o.Identifiers = o.Identifiers.UpdateFiletype(true)
+
+	tmpDir, err := os.MkdirTemp(o.TmpDir, "unpack")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	u := &unpacker{options: o, tmpDir: tmpDir}
+	root := &Node{Path: path}
+	if err := u.identify(root, r); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// containers that entries can be unpacked from
+const (
+	tarMime  = "application/x-tar"
+	zipMime  = "application/zip"
+	arMime   = "application/x-archive"
+	cpioMime = "application/x-cpio"
+)
+
+// identify calculates the identifiers of r and unpacks it if its a container
+// errors reading r are returned, errors unpacking the container are stored on the node
+func (u *unpacker) identify(node *Node, r io.Reader) error {
+	head := make([]byte, filetype.MaxBytesFileDetect())
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		node.Error = err.Error()
+		return err
+	}
+	head = head[:n]
+	r = io.MultiReader(bytes.NewReader(head), r)
+
+	ftype, codec, err := decompress.Detect(bytes.NewReader(head))
+	if err != nil {
+		return err
+	}
+	unpack := node.Depth() < u.options.MaxDepth &&
+		(codec != decompress.None || ftype.Is(tarMime, zipMime, arMime, cpioMime))
+
+	w := u.options.Identifiers.NewWriter()
+	var spool *buffer.Spool
+	if unpack {
+		u.count++
+		spool, err = buffer.NewSpool(filepath.Join(u.tmpDir, strconv.Itoa(u.count)), u.options.MemorySize)
+		if err != nil {
+			return err
+		}
+		defer spool.Delete()
+		w.AddWriter(spool)
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		node.Error = err.Error()
+		return err
+	}
+	w.Close()
+	node.Identifiers, err = w.Identifiers()
+	if err != nil || !unpack {
+		return err
+	}
+
+	if err := spool.Close(); err != nil {
+		return err
+	}
+	data, err := spool.NewReader()
+	if err != nil {
+		return err
+	}
+	defer data.Close()
+
+	if err := u.unpack(node, data, codec, ftype); err != nil {
+		node.Error = err.Error()
+	}
+	return nil
+}
+
+func (u *unpacker) unpack(node *Node, data buffer.SpoolReader, codec decompress.Codec, ftype filetype.Filetype) error {
+	if codec != decompress.None {
+		r, err := decompress.NewReader(data)
+		if err != nil {
+			return err
+		}
+		name := path.Base(node.Path)
+		if trimmed := strings.TrimSuffix(name, ftype.Extension); trimmed != "" {
+			name = trimmed
+		}
+		return u.identify(node.addChild(path.Join(node.Path, name)), r)
+	}
+
+	switch {
+	case ftype.Is(tarMime):
+		return u.unpackTar(node, data)
+	case ftype.Is(zipMime):
+		return u.unpackZip(node, data)
+	case ftype.Is(arMime):
+		return u.unpackAr(node, data)
+	case ftype.Is(cpioMime):
+		return u.unpackCpio(node, data)
+	}
+	return nil
+}
+
+// entry adds a child for an entry in an archive, directories and links are not read
+func (u *unpacker) entry(node *Node, name string, mode fs.FileMode, link bool, r io.Reader) error {
+	p := path.Join(node.Path, name)
+	switch {
+	case mode.IsDir():
+		node.addChild(p).Identifiers = identifiers.Identifiers{Filetype: filetype.Dir}
+		return nil
+	case link || mode&fs.ModeSymlink != 0:
+		node.addChild(p).Identifiers = identifiers.Identifiers{Filetype: filetype.Symlink}
+		return nil
+	case !mode.IsRegular():
+		// devices, pipes etc have no data
+		return nil
+	}
+	return u.identify(node.addChild(p), r)
+}
+
+func (u *unpacker) unpackTar(node *Node, data buffer.SpoolReader) error {
+	tr := tar.NewReader(data)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		link := hdr.Typeflag == tar.TypeLink || hdr.Typeflag == tar.TypeSymlink
+		if err := u.entry(node, hdr.Name, hdr.FileInfo().Mode(), link, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func (u *unpacker) unpackZip(node *Node, data buffer.SpoolReader) error {
+	zr, err := zip.NewReader(data, node.Identifiers.Size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		mode := f.Mode()
+		if mode.IsDir() || mode&fs.ModeSymlink != 0 {
+			if err := u.entry(node, f.Name, mode, false, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = u.entry(node, f.Name, mode, false, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *unpacker) unpackAr(node *Node, data buffer.SpoolReader) error {
+	arr := ar.NewReader(data)
+	for {
+		hdr, err := arr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := u.entry(node, hdr.Name, hdr.Mode, false, arr); err != nil {
+			return err
+		}
+	}
+}
+
+func (u *unpacker) unpackCpio(node *Node, data buffer.SpoolReader) error {
+	cr := cpio.NewReader(data)
+	for {
+		hdr, err := cr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := u.entry(node, hdr.Name, hdr.Mode, false, cr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package unpack
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jonathongardner/fifo/filetype"
+)
+
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func zipArchive(files map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, data := range files {
+		f, err := writer.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write([]byte(data)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func tarArchive(zipData []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	headers := []struct {
+		hdr  *tar.Header
+		data []byte
+	}{
+		{&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755}, nil},
+		{&tar.Header{Name: "dir/foo.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 14}, []byte("Something cool")},
+		{&tar.Header{Name: "dir/link", Typeflag: tar.TypeSymlink, Linkname: "foo.txt"}, nil},
+		{&tar.Header{Name: "inner.zip", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(zipData))}, zipData},
+	}
+	for _, h := range headers {
+		if err := writer.WriteHeader(h.hdr); err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(h.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func assertNode(t *testing.T, n *Node, path, mime string, children int) {
+	t.Helper()
+	if n.Path != path {
+		t.Errorf("expected %s path, got %s", path, n.Path)
+	}
+	if n.Identifiers.Filetype.Mimetype != mime {
+		t.Errorf("expected %s mimetype for %s, got %s", mime, path, n.Identifiers.Filetype.Mimetype)
+	}
+	if len(n.Children) != children {
+		t.Fatalf("expected %d children for %s, got %d", children, path, len(n.Children))
+	}
+	if n.Error != "" {
+		t.Errorf("expected no error for %s, got %s", path, n.Error)
+	}
+	for _, c := range n.Children {
+		if c.Parent != n {
+			t.Errorf("expected parent of %s to be %s", c.Path, path)
+		}
+	}
+}
+
+func TestUnpack(t *testing.T) {
+	zipData, err := zipArchive(map[string]string{"bar.txt": "Something else cool"})
+	if err != nil {
+		t.Fatalf("failed to create zip %v", err)
+	}
+	tarData, err := tarArchive(zipData)
+	if err != nil {
+		t.Fatalf("failed to create tar %v", err)
+	}
+	gzData, err := gzipCompress(tarData)
+	if err != nil {
+		t.Fatalf("failed to compress tar %v", err)
+	}
+
+	assertTree := func(t *testing.T, root *Node) {
+		assertNode(t, root, "foo.tar.gz", "application/gzip", 1)
+		tarNode := root.Children[0]
+		assertNode(t, tarNode, "foo.tar.gz/foo.tar", "application/x-tar", 4)
+		assertNode(t, tarNode.Children[0], "foo.tar.gz/foo.tar/dir", filetype.Dir.Mimetype, 0)
+		assertNode(t, tarNode.Children[1], "foo.tar.gz/foo.tar/dir/foo.txt", "text/plain; charset=utf-8", 0)
+		assertNode(t, tarNode.Children[2], "foo.tar.gz/foo.tar/dir/link", filetype.Symlink.Mimetype, 0)
+		zipNode := tarNode.Children[3]
+		assertNode(t, zipNode, "foo.tar.gz/foo.tar/inner.zip", "application/zip", 1)
+		assertNode(t, zipNode.Children[0], "foo.tar.gz/foo.tar/inner.zip/bar.txt", "text/plain; charset=utf-8", 0)
+
+		if tarNode.Children[1].Identifiers.Size != 14 {
+			t.Errorf("expected 14 size, got %d", tarNode.Children[1].Identifiers.Size)
+		}
+		if tarNode.Children[1].Identifiers.Md5 != "db5ee56e2cab72f4e46bdd60965bef31" {
+			t.Errorf("expected md5 db5ee56e2cab72f4e46bdd60965bef31, got %s", tarNode.Children[1].Identifiers.Md5)
+		}
+		if zipNode.Children[0].Depth() != 3 {
+			t.Errorf("expected 3 depth, got %d", zipNode.Children[0].Depth())
+		}
+	}
+
+	t.Run("in memory", func(t *testing.T) {
+		root, err := Unpack(bytes.NewReader(gzData), "foo.tar.gz")
+		if err != nil {
+			t.Fatalf("failed to unpack %v", err)
+		}
+		assertTree(t, root)
+	})
+
+	t.Run("temp files", func(t *testing.T) {
+		tmpDir, err := os.MkdirTemp("", "tmp")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		o := NewDefultOptions().UpdateMemorySize(10).UpdateTmpDir(tmpDir)
+		root, err := o.Unpack(bytes.NewReader(gzData), "foo.tar.gz")
+		if err != nil {
+			t.Fatalf("failed to unpack %v", err)
+		}
+		assertTree(t, root)
+
+		entries, err := os.ReadDir(tmpDir)
+		if err != nil {
+			t.Fatalf("failed to read temp dir %v", err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("expected temp files to be cleaned up, got %d", len(entries))
+		}
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		root, err := NewDefultOptions().UpdateMaxDepth(2).Unpack(bytes.NewReader(gzData), "foo.tar.gz")
+		if err != nil {
+			t.Fatalf("failed to unpack %v", err)
+		}
+		tarNode := root.Children[0]
+		assertNode(t, tarNode, "foo.tar.gz/foo.tar", "application/x-tar", 4)
+		assertNode(t, tarNode.Children[3], "foo.tar.gz/foo.tar/inner.zip", "application/zip", 0)
+	})
+
+	t.Run("corrupt container", func(t *testing.T) {
+		root, err := Unpack(bytes.NewReader(gzData[:len(gzData)/2]), "foo.tar.gz")
+		if err != nil {
+			t.Fatalf("failed to unpack %v", err)
+		}
+		if root.Error == "" {
+			t.Errorf("expected an error unpacking a corrupt gzip")
+		}
+	})
+
+	t.Run("count nodes", func(t *testing.T) {
+		root, err := Unpack(bytes.NewReader(gzData), "foo.tar.gz")
+		if err != nil {
+			t.Fatalf("failed to unpack %v", err)
+		}
+		count := 0
+		root.Walk(func(n *Node) error {
+			count++
+			return nil
+		})
+		if count != 7 {
+			t.Errorf("expected 7 nodes, got %d", count)
+		}
+	})
+}
+
+func TestUnpackArCpio(t *testing.T) {
+	t.Run("ar", func(t *testing.T) {
+		var buf bytes.Buffer
+		buf.WriteString("!<arch>\n")
+		fmt.Fprintf(&buf, "%-16s%-12d%-6d%-6d%-8o%-10d`\n", "foo.txt/", 0, 0, 0, 0644, 14)
+		buf.WriteString("Something cool")
+
+		root, err := Unpack(&buf, "foo.a")
+		if err != nil {
+			t.Fatalf("failed to unpack %v", err)
+		}
+		assertNode(t, root, "foo.a", "application/x-archive", 1)
+		assertNode(t, root.Children[0], "foo.a/foo.txt", "text/plain; charset=utf-8", 0)
+	})
+
+	t.Run("cpio", func(t *testing.T) {
+		var buf bytes.Buffer
+		for _, f := range []struct{ name, data string }{{"foo.txt", "Something cool"}, {"TRAILER!!!", ""}} {
+			fmt.Fprintf(&buf, "070707%06o%06o%06o%06o%06o%06o%06o%011o%06o%011o", 0, 0, 0100644, 0, 0, 1, 0, 0, len(f.name)+1, len(f.data))
+			buf.WriteString(f.name + "\x00" + f.data)
+		}
+
+		root, err := Unpack(&buf, "foo.cpio")
+		if err != nil {
+			t.Fatalf("failed to unpack %v", err)
+		}
+		assertNode(t, root, "foo.cpio", "application/x-cpio", 1)
+		assertNode(t, root.Children[0], "foo.cpio/foo.txt", "text/plain; charset=utf-8", 0)
+	})
+}
@@ -0,0 +1,50 @@
+package zlib
+
+import (
+	"compress/zlib"
+	"io"
+
+	"github.com/jonathongardner/fifo"
+)
+
+var _ fifo.ReadCloseReseter = (*Reader)(nil)
+
+// Reader is a zlib reader that can be reset back to the start of the stream
+type Reader struct {
+	zr io.ReadCloser
+	r  io.ReadSeeker
+}
+
+// NewReader Creates a new zlib reader
+// r must be a ReadSeeker, needed to seek to reset the reader
+func NewReader(r io.ReadSeeker) (*Reader, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{zr: zr, r: r}, nil
+}
+
+// Read reads data from the zlib reader
+func (r *Reader) Read(p []byte) (int, error) {
+	return r.zr.Read(p)
+}
+
+// Close closes the zlib reader and the underlying reader if it implements io.Closer
+func (r *Reader) Close() error {
+	if err := r.zr.Close(); err != nil {
+		return err
+	}
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Reset resets the zlib reader to the beginning of the stream
+func (r *Reader) Reset() error {
+	if _, err := r.r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return r.zr.(zlib.Resetter).Reset(r.r, nil)
+}
@@ -0,0 +1,68 @@
+package zlib
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"testing"
+)
+
+func zlibCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := zlib.NewWriter(&buf)
+	_, err := writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func TestZlibReader(t *testing.T) {
+	toWrite := []byte("Your String Here")
+	compressed, err := zlibCompress(toWrite)
+	if err != nil {
+		t.Fatalf("failed to compress data %v", err)
+	}
+	r := bytes.NewReader(compressed)
+	reader, err := NewReader(r)
+	if err != nil {
+		t.Fatalf("failed to create new zlib reader %v", err)
+	}
+
+	v1, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal("failed to read zlib", err)
+	}
+	if string(v1) != string(toWrite) {
+		t.Fatalf("expected %s but got %s", string(toWrite), string(v1))
+	}
+
+	v2 := make([]byte, len(toWrite))
+	n2, err := reader.Read(v2)
+	if err != io.EOF {
+		t.Fatal("expected EOF", err)
+	}
+	if n2 != 0 {
+		t.Fatalf("expected 0 bytes but got %d", n2)
+	}
+
+	if err := reader.Reset(); err != nil {
+		t.Fatal("failed to reset zlib", err)
+	}
+
+	v3, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal("failed to read zlib after reset", err)
+	}
+	if string(v3) != string(toWrite) {
+		t.Fatalf("expected %s but got %s after reset", string(toWrite), string(v3))
+	}
+
+	if err := reader.Close(); err != nil {
+		t.Fatal("failed to close zlib", err)
+	}
+}